@@ -0,0 +1,96 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "errors"
+
+// A Block is a contiguous range of ULIDs reserved by Generator.Block, which
+// can be handed out without synchronization. A Block isn't safe for
+// concurrent use.
+type Block struct {
+	next ULID
+	n    int
+}
+
+// Block reserves n consecutive ULIDs for the current time and returns them as
+// a Block. The ULIDs of a Block are greater than all the ULIDs previously
+// generated by g, and smaller than all the ULIDs it generates afterwards, so
+// Blocks never overlap with each other or with ULIDs returned by New.
+//
+// The first ULID of a Block is generated like by New, and the following ones
+// increment its entropy by one each, so they're trivially guessable from one
+// another. ErrMonotonicOverflow is returned if the Block doesn't fit into the
+// remaining entropy of the timestamp. Otherwise, it returns the same errors
+// as New.
+func (g *Generator) Block(n int) (*Block, error) {
+	if n < 1 {
+		return nil, errors.New("ulid: block size must be positive")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.load(); err != nil {
+		return nil, err
+	}
+
+	t := g.now()
+	first, err := g.next(t, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = g.autosave(t); err != nil {
+		return nil, err
+	}
+
+	return &Block{next: first, n: n}, nil
+}
+
+// add returns id with n added to its entropy, leaving the reserved high bits
+// untouched.
+func (g *Generator) add(id ULID, n uint64) (ULID, error) {
+	var e uint80
+	e.SetBytes(id[6:])
+	prefix := e.Top(g.reserved)
+	if e.Add(n) || e.Top(g.reserved) != prefix {
+		return id, ErrMonotonicOverflow
+	}
+
+	e.AppendTo(id[6:])
+	return id, nil
+}
+
+// Next returns the next ULID of b, in increasing order. It returns false when
+// b is exhausted.
+func (b *Block) Next() (id ULID, ok bool) {
+	if b.n == 0 {
+		return id, false
+	}
+
+	id = b.next
+	if b.n--; b.n > 0 {
+		var e uint80
+		e.SetBytes(b.next[6:])
+		e.Add(1)
+		e.AppendTo(b.next[6:])
+	}
+
+	return id, true
+}
+
+// Len returns the number of ULIDs left in b.
+func (b *Block) Len() int {
+	return b.n
+}
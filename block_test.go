@@ -0,0 +1,169 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestGeneratorBlock(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	g, err := ulid.NewGenerator(ulid.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := g.MustNew()
+	b, err := g.Block(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := g.MustNew()
+
+	prev := before
+	for i := 0; i < 1000; i++ {
+		if got, want := b.Len(), 1000-i; got != want {
+			t.Fatalf("Len: got %d, want %d", got, want)
+		}
+
+		id, ok := b.Next()
+		if !ok {
+			t.Fatalf("Next: exhausted after %d ULIDs", i)
+		}
+
+		if i > 0 && binary.BigEndian.Uint64(id[8:])-binary.BigEndian.Uint64(prev[8:]) != 1 {
+			t.Fatalf("ULID %d: %v doesn't follow %v", i, id, prev)
+		}
+
+		if prev.Compare(id) >= 0 {
+			t.Fatalf("ULID %d: %v >= %v", i, prev, id)
+		}
+		prev = id
+	}
+
+	if id, ok := b.Next(); ok {
+		t.Errorf("Next: got %v after exhaustion", id)
+	}
+
+	if prev.Compare(after) >= 0 {
+		t.Errorf("last block ULID %v >= following ULID %v", prev, after)
+	}
+
+	if got, want := g.Stats().Generated, uint64(1002); got != want {
+		t.Errorf("Generated: got %d, want %d", got, want)
+	}
+
+	if _, err := g.Block(0); err == nil {
+		t.Error("Block(0): got no error")
+	}
+}
+
+func TestGeneratorBlockConcurrency(t *testing.T) {
+	t.Parallel()
+
+	g, err := ulid.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const workers, blocks, size = 8, 50, 100
+	var (
+		mu   sync.Mutex
+		seen = make(map[ulid.ULID]bool)
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var ids []ulid.ULID
+			for j := 0; j < blocks; j++ {
+				b, err := g.Block(size)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for id, ok := b.Next(); ok; id, ok = b.Next() {
+					ids = append(ids, id)
+				}
+				ids = append(ids, g.MustNew())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				if seen[id] {
+					t.Errorf("duplicate ULID %v", id)
+				}
+				seen[id] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := len(seen), workers*blocks*(size+1); got != want {
+		t.Errorf("got %d distinct ULIDs, want %d", got, want)
+	}
+}
+
+func TestGeneratorBlockOverflow(t *testing.T) {
+	t.Parallel()
+
+	// Fresh entropy 15 increments away from overflow.
+	entropy := func() io.Reader {
+		return io.MultiReader(
+			bytes.NewReader(append(bytes.Repeat([]byte{0xFF}, 9), 0xF0)),
+			crand.Reader,
+		)
+	}
+
+	g, err := ulid.NewGenerator(ulid.WithEntropy(entropy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Block(16); err != nil {
+		t.Errorf("Block(16): got err %v, want nil", err)
+	}
+
+	g, err = ulid.NewGenerator(ulid.WithEntropy(entropy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Block(17); err != ulid.ErrMonotonicOverflow {
+		t.Errorf("Block(17): got err %v, want %v", err, ulid.ErrMonotonicOverflow)
+	}
+
+	if got, want := g.Stats(), (ulid.Stats{Overflows: 1}); got != want {
+		t.Errorf("Stats: got %+v, want %+v", got, want)
+	}
+
+	// The failed Block must not use up any ULID.
+	state, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := state[1:17]; !bytes.Equal(last, make([]byte, 16)) {
+		t.Errorf("got last ULID %x after failed Block, want zero", last)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "time"
+
+// Bucket returns the start time and key of the time bucket of width d that
+// contains the ULID's timestamp. Buckets are aligned to the Unix epoch, so the
+// key is the number of whole buckets between the epoch and the timestamp.
+// Widths are truncated to whole milliseconds.
+//
+// Bucket panics if d is less than one millisecond.
+func (id ULID) Bucket(d time.Duration) (start time.Time, key uint64) {
+	w := bucketWidth(d)
+	key = id.Time() / w
+	return Time(key * w), key
+}
+
+// A Bucket is a time bucket as yielded by a BucketIterator.
+type Bucket struct {
+	// Key is the bucket's key, as returned by ULID.Bucket.
+	Key uint64
+
+	// Start is the start time of the bucket.
+	Start time.Time
+
+	// Min and Max are the smallest and largest ULIDs in the bucket, inclusive.
+	Min, Max ULID
+}
+
+// BucketIterator iterates over consecutive time buckets. See Buckets.
+type BucketIterator struct {
+	key, last uint64
+	width     uint64
+	done      bool
+	bucket    Bucket
+}
+
+// Buckets returns an iterator over all the buckets of width d that overlap
+// with the half-open range [from, to), in Unix milliseconds. Buckets are
+// aligned the same way as in ULID.Bucket.
+//
+// Buckets panics if d is less than one millisecond.
+func Buckets(from, to uint64, d time.Duration) *BucketIterator {
+	it := &BucketIterator{width: bucketWidth(d)}
+	if to > maxTime+1 {
+		to = maxTime + 1
+	}
+	if from >= to {
+		it.done = true
+		return it
+	}
+	it.key, it.last = from/it.width, (to-1)/it.width
+	return it
+}
+
+// Next advances the iterator to the next bucket, which will then be available
+// through the Bucket method. It returns false when there are no more buckets.
+func (it *BucketIterator) Next() bool {
+	if it.done || it.key > it.last {
+		it.done = true
+		return false
+	}
+
+	start := it.key * it.width
+	it.bucket = Bucket{
+		Key:   it.key,
+		Start: Time(start),
+		Min:   MinAt(start),
+		Max:   MaxAt(start + it.width - 1),
+	}
+	it.key++
+
+	return true
+}
+
+// Bucket returns the current bucket.
+func (it *BucketIterator) Bucket() Bucket {
+	return it.bucket
+}
+
+func bucketWidth(d time.Duration) uint64 {
+	if d < time.Millisecond {
+		panic("ulid: bucket width less than one millisecond")
+	}
+	return uint64(d / time.Millisecond)
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 3, 15, 13, 45, 12, 345e6, time.UTC)
+	id := ulid.MustNew(ulid.Timestamp(ts), nil)
+
+	for _, tc := range []struct {
+		d     time.Duration
+		start time.Time
+		key   uint64
+	}{
+		{time.Millisecond, ts, ulid.Timestamp(ts)},
+		{time.Hour, ts.Truncate(time.Hour), uint64(ts.Unix() / 3600)},
+		{24 * time.Hour, ts.Truncate(24 * time.Hour), uint64(ts.Unix() / 86400)},
+	} {
+		start, key := id.Bucket(tc.d)
+		if !start.Equal(tc.start) {
+			t.Errorf("Bucket(%v): got start %v, want %v", tc.d, start, tc.start)
+		}
+		if key != tc.key {
+			t.Errorf("Bucket(%v): got key %d, want %d", tc.d, key, tc.key)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Bucket(0): expected panic")
+		}
+	}()
+	id.Bucket(0)
+}
+
+func TestBuckets(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	from, to := ulid.Timestamp(day), ulid.Timestamp(day.Add(24*time.Hour))
+
+	var buckets []ulid.Bucket
+	for it := ulid.Buckets(from, to, time.Hour); it.Next(); {
+		buckets = append(buckets, it.Bucket())
+	}
+
+	if got, want := len(buckets), 24; got != want {
+		t.Fatalf("got %d buckets, want %d", got, want)
+	}
+
+	for i, b := range buckets {
+		if want := day.Add(time.Duration(i) * time.Hour); !b.Start.Equal(want) {
+			t.Errorf("bucket %d: got start %v, want %v", i, b.Start, want)
+		}
+		if b.Min != ulid.MinAt(ulid.Timestamp(b.Start)) {
+			t.Errorf("bucket %d: bad min %s", i, b.Min)
+		}
+		if i > 0 {
+			// Adjacent buckets must not overlap nor leave gaps.
+			if prev := buckets[i-1]; prev.Max.Time()+1 != b.Min.Time() || prev.Key+1 != b.Key {
+				t.Errorf("bucket %d: not adjacent to %d", i, i-1)
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 1000; i++ {
+		id := ulid.MustNew(from+uint64(rng.Int63n(int64(to-from))), rng)
+		_, key := id.Bucket(time.Hour)
+		b := buckets[key-buckets[0].Key]
+		if id.Compare(b.Min) < 0 || id.Compare(b.Max) > 0 {
+			t.Fatalf("%s not within bucket [%s, %s]", id, b.Min, b.Max)
+		}
+	}
+}
+
+func TestBucketsBounds(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		from, to uint64
+		d        time.Duration
+		n        int
+	}{
+		{"empty", 1000, 1000, time.Second, 0},
+		{"inverted", 2000, 1000, time.Second, 0},
+		{"unaligned", 1500, 2500, time.Second, 2},
+		{"exclusive end", 1000, 2001, time.Second, 2},
+		{"single", 1000, 2000, time.Second, 1},
+		{"max time", ulid.MaxTime() - 1, ulid.MaxTime() + 100, time.Millisecond, 2},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var n int
+			var last ulid.Bucket
+			for it := ulid.Buckets(tc.from, tc.to, tc.d); it.Next(); n++ {
+				last = it.Bucket()
+			}
+			if n != tc.n {
+				t.Errorf("got %d buckets, want %d", n, tc.n)
+			}
+			if n > 0 && last.Max.Time() > ulid.MaxTime() {
+				t.Errorf("last bucket exceeds MaxTime: %s", last.Max)
+			}
+		})
+	}
+}
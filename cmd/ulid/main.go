@@ -28,6 +28,7 @@ func main() {
 		local  = fs.BoolLong("local", 'l', "when parsing, show local time instead of UTC")
 		quick  = fs.BoolLong("quick", 'q', "when generating, use non-crypto-grade entropy")
 		zero   = fs.BoolLong("zero", 'z', "when generating, fix entropy to all-zeroes")
+		seed   = fs.StringLong("seed", 's', "", "when generating, derive entropy deterministically from this seed", "<seed>")
 		help   = fs.BoolLong("help", 'h', "print this help text")
 	)
 	if err := fs.Getopt(os.Args, nil); err != nil {
@@ -56,19 +57,22 @@ func main() {
 
 	switch args := fs.Args(); len(args) {
 	case 0:
-		generate(*quick, *zero)
+		generate(*quick, *zero, *seed)
 	default:
 		parse(args[0], *local, formatFunc)
 	}
 }
 
-func generate(quick, zero bool) {
+func generate(quick, zero bool, seed string) {
 	entropy := cryptorand.Reader
 	if quick {
 		seed := time.Now().UnixNano()
 		source := mathrand.NewSource(seed)
 		entropy = mathrand.New(source)
 	}
+	if seed != "" {
+		entropy = ulid.Seeded([]byte(seed))
+	}
 	if zero {
 		entropy = zeroReader{}
 	}
@@ -0,0 +1,92 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"io"
+	"time"
+)
+
+// A Codec converts between times and ULID timestamps counted in milliseconds
+// since a configurable epoch, rather than since the Unix epoch. The zero value
+// uses the Unix epoch, and is thus equivalent to the top level Timestamp and
+// Time functions.
+//
+// ULIDs encoded with a custom epoch are still valid ULIDs, but their Time and
+// Timestamp methods must not be used to decode them; use the Codec instead.
+type Codec struct {
+	epoch int64 // Unix milliseconds.
+}
+
+// NewCodec returns a Codec for the given epoch, truncated to milliseconds.
+// Times between the epoch and the epoch plus MaxTime milliseconds can be
+// encoded.
+func NewCodec(epoch time.Time) Codec {
+	return Codec{epoch: unixMilli(epoch)}
+}
+
+// Epoch returns the epoch of the Codec.
+func (c Codec) Epoch() time.Time {
+	return c.Time(0)
+}
+
+// Timestamp converts a time.Time to milliseconds since the Codec's epoch.
+//
+// Times before the epoch or later than the epoch plus MaxTime milliseconds
+// produce undefined results.
+func (c Codec) Timestamp(t time.Time) uint64 {
+	return uint64(unixMilli(t) - c.epoch)
+}
+
+// Time converts milliseconds since the Codec's epoch, in the format returned
+// by the Timestamp method, to a time.Time.
+func (c Codec) Time(ms uint64) time.Time {
+	// Split the computation to avoid overflowing int64 for any valid epoch.
+	s, rem := int64(ms/1e3), int64(ms%1e3)
+	return time.Unix(s+c.epoch/1e3, (rem+c.epoch%1e3)*1e6)
+}
+
+// New returns a ULID with the given time encoded relative to the Codec's
+// epoch, and an optional entropy source, like the top level New function.
+//
+// ErrSmallTime is returned when the time is before the epoch, and ErrBigTime
+// when it's later than the epoch plus MaxTime milliseconds.
+func (c Codec) New(t time.Time, entropy io.Reader) (id ULID, err error) {
+	ms, err := c.timestamp(t)
+	if err != nil {
+		return id, err
+	}
+	return New(ms, entropy)
+}
+
+// timestamp is like Timestamp, but returns ErrSmallTime for times before the
+// epoch.
+func (c Codec) timestamp(t time.Time) (uint64, error) {
+	ms := unixMilli(t)
+	if ms < c.epoch {
+		return 0, ErrSmallTime
+	}
+	return uint64(ms - c.epoch), nil
+}
+
+// TimeOf returns the time encoded in the ULID relative to the Codec's epoch,
+// as a time.Time.
+func (c Codec) TimeOf(id ULID) time.Time {
+	return c.Time(id.Time())
+}
+
+// unixMilli returns t as Unix milliseconds, like time.Time.UnixMilli.
+func unixMilli(t time.Time) int64 {
+	return t.Unix()*1e3 + int64(t.Nanosecond())/1e6
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := ulid.NewCodec(epoch)
+
+	if got := c.Epoch(); !got.Equal(epoch) {
+		t.Errorf("got epoch %v, want %v", got, epoch)
+	}
+
+	if got, want := c.Timestamp(epoch.Add(1500*time.Millisecond)), uint64(1500); got != want {
+		t.Errorf("got timestamp %d, want %d", got, want)
+	}
+
+	prop := func(ms uint64) bool {
+		ms %= ulid.MaxTime() + 1
+		return c.Timestamp(c.Time(ms)) == ms
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1e4}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The last representable time is MaxTime milliseconds after the epoch,
+	// past what the Unix epoch allows.
+	last := c.Time(ulid.MaxTime())
+	if !last.After(ulid.Time(ulid.MaxTime())) {
+		t.Errorf("got last time %v, want after %v", last, ulid.Time(ulid.MaxTime()))
+	}
+}
+
+func TestCodecZero(t *testing.T) {
+	t.Parallel()
+
+	var c ulid.Codec
+	prop := func(ms uint64) bool {
+		ms %= ulid.MaxTime() + 1
+		return c.Time(ms).Equal(ulid.Time(ms)) && c.Timestamp(ulid.Time(ms)) == ms
+	}
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1e4}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCodecNew(t *testing.T) {
+	t.Parallel()
+
+	// An epoch before 1970 allows encoding times that Unix ULIDs can't.
+	epoch := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := ulid.NewCodec(epoch)
+
+	ts := time.Date(1969, 7, 20, 20, 17, 40, 0, time.UTC)
+	id, err := c.New(ts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.TimeOf(id); !got.Equal(ts) {
+		t.Errorf("got time %v, want %v", got, ts)
+	}
+
+	if _, err := c.New(epoch.Add(-time.Millisecond), nil); err != ulid.ErrSmallTime {
+		t.Errorf("got err %v, want %v", err, ulid.ErrSmallTime)
+	}
+	if _, err := c.New(c.Time(ulid.MaxTime()).Add(time.Millisecond), nil); err != ulid.ErrBigTime {
+		t.Errorf("got err %v, want %v", err, ulid.ErrBigTime)
+	}
+}
+
+func TestGeneratorCodec(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := epoch.Add(42 * time.Hour)
+	c := ulid.NewCodec(epoch)
+
+	g, err := ulid.NewGenerator(ulid.WithCodec(c), ulid.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := g.MustNew()
+	if got := c.TimeOf(id); !got.Equal(now) {
+		t.Errorf("got time %v, want %v", got, now)
+	}
+
+	now = epoch.Add(-time.Hour)
+	g, err = ulid.NewGenerator(ulid.WithCodec(c), ulid.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.New(); err != ulid.ErrSmallTime {
+		t.Errorf("got err %v, want %v", err, ulid.ErrSmallTime)
+	}
+}
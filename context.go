@@ -0,0 +1,68 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "context"
+
+// A ContextKey identifies a ULID carried by a context.Context, such as a
+// correlation or idempotency ID. Keys are compared by identity, so distinct
+// keys never collide, even if they share a name.
+type ContextKey struct {
+	name string
+}
+
+// NewContextKey returns a new ContextKey with the given name, which is only
+// used for debugging.
+func NewContextKey(name string) *ContextKey {
+	return &ContextKey{name: name}
+}
+
+// String returns the name of the key.
+func (k *ContextKey) String() string {
+	return "ulid context key " + k.name
+}
+
+// NewContext returns a copy of ctx carrying id under the given key.
+func NewContext(ctx context.Context, key *ContextKey, id ULID) context.Context {
+	return context.WithValue(ctx, key, id)
+}
+
+// FromContext returns the ULID carried by ctx under the given key, if any.
+func FromContext(ctx context.Context, key *ContextKey) (id ULID, ok bool) {
+	id, ok = ctx.Value(key).(ULID)
+	return id, ok
+}
+
+type generatorKey struct{}
+
+// NewGeneratorContext returns a copy of ctx carrying g, so that libraries can
+// generate ULIDs with the application's Generator via NewFromContext.
+func NewGeneratorContext(ctx context.Context, g *Generator) context.Context {
+	return context.WithValue(ctx, generatorKey{}, g)
+}
+
+// GeneratorFromContext returns the Generator carried by ctx, if any.
+func GeneratorFromContext(ctx context.Context) (g *Generator, ok bool) {
+	g, ok = ctx.Value(generatorKey{}).(*Generator)
+	return g, ok && g != nil
+}
+
+// NewFromContext returns a new ULID from the Generator carried by ctx. Without
+// one, it falls back to the current time and DefaultEntropy.
+func NewFromContext(ctx context.Context) (ULID, error) {
+	if g, ok := GeneratorFromContext(ctx); ok {
+		return g.New()
+	}
+	return New(Now(), DefaultEntropy())
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+
+	var (
+		correlation = ulid.NewContextKey("correlation")
+		idempotency = ulid.NewContextKey("correlation")
+		id          = ulid.Make()
+		ctx         = ulid.NewContext(context.Background(), correlation, id)
+	)
+
+	if got, ok := ulid.FromContext(ctx, correlation); !ok || got != id {
+		t.Errorf("FromContext(correlation): got %v, %v, want %v, true", got, ok, id)
+	}
+
+	if got, ok := ulid.FromContext(ctx, idempotency); ok {
+		t.Errorf("FromContext(idempotency): got %v, true, want false", got)
+	}
+}
+
+func TestNewFromContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	if _, ok := ulid.GeneratorFromContext(ctx); ok {
+		t.Fatal("GeneratorFromContext: got a generator from an empty context")
+	}
+
+	if _, err := ulid.NewFromContext(ctx); err != nil {
+		t.Fatalf("NewFromContext without generator: %v", err)
+	}
+
+	now := time.Unix(1e9, 0)
+	g, err := ulid.NewGenerator(ulid.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = ulid.NewGeneratorContext(ctx, g)
+	if got, ok := ulid.GeneratorFromContext(ctx); !ok || got != g {
+		t.Fatalf("GeneratorFromContext: got %p, %v, want %p, true", got, ok, g)
+	}
+
+	id, err := ulid.NewFromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := id.Time(), ulid.Timestamp(now); got != want {
+		t.Errorf("Time: got %d, want %d", got, want)
+	}
+
+	if got, want := g.Stats().Generated, uint64(1); got != want {
+		t.Errorf("Generated: got %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"bytes"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"sync"
+)
+
+// DefaultBufferSize is the buffer size used by Buffered when none is given.
+const DefaultBufferSize = 4096
+
+// Buffered returns a source of entropy that reads from the given entropy
+// source in bulk, into a buffer of size bytes, and serves reads from that
+// buffer until it is exhausted. Each buffered byte is handed out only once.
+// If entropy is nil, crypto/rand.Reader is used, which makes for a secure
+// entropy source that amortizes the cost of system calls over many ULIDs. If
+// size is not positive, DefaultBufferSize is used.
+//
+// Reads larger than the buffer bypass it and go to the underlying source
+// directly.
+//
+// The returned type is safe for concurrent use.
+func Buffered(entropy io.Reader, size int) *BufferedEntropy {
+	if entropy == nil {
+		entropy = crand.Reader
+	}
+
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	buf := make([]byte, size)
+	return &BufferedEntropy{
+		src: entropy,
+		buf: buf,
+		off: len(buf), // Filled on first read.
+	}
+}
+
+// BufferedEntropy is an opaque type that provides buffered entropy.
+type BufferedEntropy struct {
+	mu  sync.Mutex
+	src io.Reader
+	buf []byte
+	off int
+}
+
+// Read implements the io.Reader interface.
+func (b *BufferedEntropy) Read(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for n < len(p) {
+		if b.off == len(b.buf) {
+			if len(p)-n >= len(b.buf) {
+				m, err := io.ReadFull(b.src, p[n:])
+				return n + m, err
+			}
+
+			if _, err = io.ReadFull(b.src, b.buf); err != nil {
+				return n, err
+			}
+			b.off = 0
+		}
+
+		c := copy(p[n:], b.buf[b.off:])
+		b.off += c
+		n += c
+	}
+
+	return n, nil
+}
+
+// Seeded returns a deterministic source of entropy that yields the same stream
+// of bytes for the same seed, regardless of platform and Go version. It's
+// meant for reproducible tests and fixtures, e.g. when wrapped by Monotonic.
+// ULIDs are only as unpredictable as the seed, so it must not be used with
+// guessable seeds where that matters.
+//
+// The stream is generated with HMAC-SHA256, as in the HMAC_DRBG construction
+// of NIST SP 800-90A: the state is instantiated from the seed, and every
+// 32 byte block of output is the HMAC of the previous one. Unlike HMAC_DRBG,
+// the state isn't updated between reads, so the stream doesn't depend on how
+// it is split into reads.
+//
+// The returned type isn't safe for concurrent use.
+func Seeded(seed []byte) *SeededEntropy {
+	key := make([]byte, sha256.Size)
+	v := bytes.Repeat([]byte{0x01}, sha256.Size)
+
+	// HMAC_DRBG_Update with the seed as provided data.
+	for _, sep := range []byte{0x00, 0x01} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(v)
+		mac.Write([]byte{sep})
+		mac.Write(seed)
+		key = mac.Sum(key[:0])
+
+		mac = hmac.New(sha256.New, key)
+		mac.Write(v)
+		v = mac.Sum(v[:0])
+	}
+
+	return &SeededEntropy{
+		mac: hmac.New(sha256.New, key),
+		v:   v,
+		off: len(v),
+	}
+}
+
+// SeededEntropy is an opaque type that provides deterministic entropy.
+type SeededEntropy struct {
+	mac hash.Hash
+	v   []byte
+	off int
+}
+
+// Read implements the io.Reader interface. It never returns an error.
+func (s *SeededEntropy) Read(p []byte) (n int, err error) {
+	for n < len(p) {
+		if s.off == len(s.v) {
+			s.mac.Reset()
+			s.mac.Write(s.v)
+			s.v = s.mac.Sum(s.v[:0])
+			s.off = 0
+		}
+
+		c := copy(p[n:], s.v[s.off:])
+		s.off += c
+		n += c
+	}
+
+	return n, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestBuffered(t *testing.T) {
+	t.Parallel()
+
+	src := &countingReader{}
+	entropy := ulid.Buffered(src, 64)
+
+	var got []byte
+	for i := 0; i < 100; i++ {
+		id, err := ulid.New(ulid.Now(), entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, id.Entropy()...)
+	}
+
+	// Bytes must be handed out in order, exactly once.
+	want := make([]byte, len(got))
+	_, _ = (&countingReader{}).Read(want)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got entropy %x, want %x", got, want)
+	}
+
+	if got, want := src.reads, (len(got)+63)/64; got != want {
+		t.Errorf("got %d reads from the source, want %d", got, want)
+	}
+
+	// Reads larger than the buffer go to the source directly.
+	reads := src.reads
+	if _, err := io.ReadFull(entropy, make([]byte, 1024)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := src.reads, reads+1; got > want {
+		t.Errorf("got %d reads from the source, want at most %d", got, want)
+	}
+}
+
+func TestBufferedError(t *testing.T) {
+	t.Parallel()
+
+	errBroken := errors.New("broken")
+	entropy := ulid.Buffered(io.MultiReader(
+		bytes.NewReader(make([]byte, 16)),
+		&errReader{errBroken},
+	), 16)
+
+	if _, err := ulid.New(0, entropy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ulid.New(0, entropy); err != errBroken {
+		t.Errorf("got err %v, want %v", err, errBroken)
+	}
+}
+
+func TestBufferedSafe(t *testing.T) {
+	t.Parallel()
+
+	entropy := ulid.Buffered(nil, 0)
+
+	var (
+		mu   sync.Mutex
+		seen = map[ulid.ULID]bool{}
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := ulid.MustNew(123, entropy)
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate ULID %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkBuffered(b *testing.B) {
+	entropy := ulid.Buffered(nil, 0)
+	b.ReportAllocs()
+	b.SetBytes(16)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = ulid.MustNew(123, entropy)
+		}
+	})
+}
+
+func ExampleSeeded() {
+	entropy := ulid.Monotonic(ulid.Seeded([]byte("fixtures")), 0)
+	ms := ulid.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for i := 0; i < 3; i++ {
+		fmt.Println(ulid.MustNew(ms, entropy))
+	}
+	// Output:
+	// 01HK153X004C5WRPCYNR0G33VN
+	// 01HK153X004C5WRPCYNS4VQ393
+	// 01HK153X004C5WRPCYNWM5X544
+}
+
+func TestSeeded(t *testing.T) {
+	t.Parallel()
+
+	// Pinned output, which must never change.
+	want, _ := hex.DecodeString("258602773c49312956123b925bf5ebcba1abd0c3" +
+		"e5753c5a36b727a555c31efb2b09b2068624df5c")
+
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(ulid.Seeded([]byte("ulid")), got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got stream %x, want %x", got, want)
+	}
+
+	// The stream must not depend on how it's read.
+	got = make([]byte, len(want))
+	if _, err := io.ReadFull(iotest.OneByteReader(ulid.Seeded([]byte("ulid"))), got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got byte-wise stream %x, want %x", got, want)
+	}
+
+	other := make([]byte, len(want))
+	if _, err := io.ReadFull(ulid.Seeded([]byte("ulie")), other); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(other, want) {
+		t.Error("different seeds yielded the same stream")
+	}
+}
+
+// countingReader yields the sequence of bytes 0, 1, 2, ... and counts reads.
+type countingReader struct {
+	n     byte
+	reads int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	for i := range p {
+		p[i] = r.n
+		r.n++
+	}
+	return len(p), nil
+}
+
+type errReader struct{ err error }
+
+func (r *errReader) Read([]byte) (int, error) { return 0, r.err }
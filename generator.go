@@ -0,0 +1,420 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// A Generator generates strictly increasing ULIDs for the current time.
+//
+// ULIDs generated within the same timestamp as the previous one get its
+// entropy incremented by a random number between 1 and 2^32 inclusive, like
+// Monotonic with the default increment. If the clock goes back, the Generator
+// keeps using the timestamp of the last ULID it generated, so that every ULID
+// is greater than the previous one.
+//
+// A Generator is safe for concurrent use.
+type Generator struct {
+	stats    counters
+	mu       sync.Mutex
+	now      func() time.Time
+	codec    Codec
+	entropy  io.Reader
+	nanos    bool
+	node     uint64
+	reserved uint // High entropy bits not subject to increments.
+	last     ULID
+	observed bool // Whether last was observed rather than generated by g.
+	rand     [4]byte
+	store    StateStore
+	interval time.Duration
+	loaded   bool
+	saved    time.Time
+	floor    uint64 // Timestamp to continue from after a restart.
+}
+
+// A GeneratorOption configures a Generator.
+type GeneratorOption func(*Generator) error
+
+// WithClock configures a Generator to read the current time from now instead
+// of time.Now.
+func WithClock(now func() time.Time) GeneratorOption {
+	return func(g *Generator) error {
+		if now == nil {
+			return errors.New("ulid: nil clock")
+		}
+		g.now = now
+		return nil
+	}
+}
+
+// WithCodec configures a Generator to encode timestamps with the given Codec,
+// e.g. one with a custom epoch.
+func WithCodec(c Codec) GeneratorOption {
+	return func(g *Generator) error {
+		g.codec = c
+		return nil
+	}
+}
+
+// WithEntropy configures a Generator to read entropy from the given source
+// instead of a Reseeding source seeded from crypto/rand. The Generator
+// serializes reads, so the source doesn't need to be safe for concurrent use
+// unless it's shared with other users.
+func WithEntropy(entropy io.Reader) GeneratorOption {
+	return func(g *Generator) error {
+		if entropy == nil {
+			return errors.New("ulid: nil entropy")
+		}
+		g.entropy = entropy
+		return nil
+	}
+}
+
+// WithSubMillisecond configures a Generator to store the sub-millisecond
+// fraction of the current time in the top 12 bits of the entropy, similarly to
+// method 3 of RFC 9562 for UUIDv7. This orders ULIDs from different processes
+// at a granularity of about 245 nanoseconds, assuming synchronized clocks, at
+// the cost of 12 bits of entropy. Use ULID.TimeNanos to read the time back.
+func WithSubMillisecond() GeneratorOption {
+	return func(g *Generator) error {
+		g.nanos = true
+		return nil
+	}
+}
+
+// WithNode configures a Generator to reserve the given number of high bits of
+// the entropy for a node ID, e.g. to tell apart instances in a fleet. The
+// remaining bits are random and incremented as usual, so ULIDs of generators
+// with different node IDs never collide. Use ULID.Node to read the node ID
+// back.
+//
+// The number of bits must be between 1 and 32, and the node ID must fit in
+// them. It can't be combined with WithSubMillisecond.
+func WithNode(node uint64, bits uint) GeneratorOption {
+	return func(g *Generator) error {
+		if bits < 1 || bits > 32 {
+			return errors.New("ulid: node bits out of range [1, 32]")
+		}
+		if node >= 1<<bits {
+			return errors.New("ulid: node ID doesn't fit in node bits")
+		}
+		g.node, g.reserved = node, bits
+		return nil
+	}
+}
+
+// WithStateStore configures a Generator to load its state from the given
+// store before generating its first ULID, and to save it again whenever the
+// given interval elapsed since the previous save. A zero interval saves after
+// every ULID. Saves are synchronous, so short intervals slow down generation.
+//
+// Each save accounts for the ULIDs generated until the next one by recording
+// the end of the interval, and a restarted Generator continues after it. So
+// after a restart within the interval of the last save, ULIDs are ahead of the
+// clock by up to the interval, until it catches up. Use Checkpoint to save the
+// state on shutdown.
+func WithStateStore(store StateStore, interval time.Duration) GeneratorOption {
+	return func(g *Generator) error {
+		if store == nil {
+			return errors.New("ulid: nil state store")
+		}
+		g.store, g.interval = store, interval
+		return nil
+	}
+}
+
+// NewGenerator returns a Generator configured with the given options.
+func NewGenerator(opts ...GeneratorOption) (*Generator, error) {
+	g := &Generator{now: time.Now}
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.entropy == nil {
+		g.entropy = Reseeding(nil, 0)
+	}
+
+	if g.nanos {
+		if g.reserved > 0 {
+			return nil, errors.New("ulid: node bits can't be combined with sub-millisecond precision")
+		}
+		g.reserved = 12
+	}
+
+	return g, nil
+}
+
+// New returns a ULID with the current time, which is greater than all the
+// ULIDs previously generated by g.
+//
+// If g has a StateStore, its state is loaded on the first call, and saved when
+// the checkpoint interval elapsed; failures to do either are returned.
+//
+// ErrBigTime is returned when the current time is bigger than MaxTime, and
+// ErrSmallTime when it's before the epoch of the Generator's Codec.
+// ErrMonotonicOverflow is returned when incrementing the previous ULID's
+// entropy would overflow. Reading from the entropy source may also return an
+// error.
+func (g *Generator) New() (id ULID, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err = g.load(); err != nil {
+		return id, err
+	}
+
+	t := g.now()
+	if id, err = g.next(t, 1); err != nil {
+		return id, err
+	}
+
+	return id, g.autosave(t)
+}
+
+// MustNew is a convenience function equivalent to New that panics on failure
+// instead of returning an error.
+func (g *Generator) MustNew() ULID {
+	id, err := g.New()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Observe advances g past the given ULID, typically one received from another
+// node, so that every ULID g generates afterwards sorts after it, even if the
+// other node's clock is ahead. Together with never going back in time, this
+// makes g a hybrid logical clock: ULIDs generated in reaction to another one
+// always sort after their cause, while staying close to physical time.
+//
+// ULIDs generated right after an observed one get fresh random entropy, in
+// its timestamp if they happen to sort after it, or in the next one, so nodes
+// reacting to the same ULID don't derive related ULIDs from it.
+//
+// Observed ULIDs in the future pull g's timestamps into the future as well,
+// so ULIDs from untrusted sources should be validated first, e.g. with a
+// Validator with NoFuture set.
+func (g *Generator) Observe(id ULID) {
+	g.mu.Lock()
+	if id.Compare(g.last) > 0 {
+		g.last, g.observed = id, true
+	}
+	g.mu.Unlock()
+}
+
+// Stats returns the counters of g. Clock regressions count the times the
+// clock went back behind the last generated ULID.
+func (g *Generator) Stats() Stats {
+	return g.stats.stats()
+}
+
+// autosave saves the state of g if it has a StateStore and the checkpoint
+// interval elapsed at time t, or the clock went back.
+func (g *Generator) autosave(t time.Time) error {
+	if g.store == nil {
+		return nil
+	}
+	if d := t.Sub(g.saved); d >= g.interval || d < 0 {
+		return g.save(t)
+	}
+	return nil
+}
+
+// next returns the first of n consecutive ULIDs following g.last for time t,
+// and records the last of them as such. Nothing is recorded if they don't all
+// fit into the entropy.
+func (g *Generator) next(t time.Time, n int) (id ULID, err error) {
+	ms, err := g.codec.timestamp(t)
+	if err != nil {
+		return id, err
+	}
+
+	var frac uint16
+	if g.nanos {
+		frac = uint16(uint64(t.Nanosecond()%1e6) << 12 / 1e6)
+	}
+
+	// Continue after the checkpoint interval of restored state.
+	if ms < g.floor {
+		ms, frac = g.floor, 0
+	}
+
+	tick, last := g.tick(ms, frac), g.tick(g.last.Time(), g.last.frac())
+	same := !g.last.IsZero() && tick <= last
+	defer func() {
+		g.stats.record(same, tick < last, err)
+		if err == nil && n > 1 {
+			g.stats.reserve(uint64(n - 1))
+		}
+	}()
+
+	switch {
+	case same && (g.observed || !g.nanos && g.last.Node(g.reserved) != g.node):
+		// The last ULID was observed, so incrementing its entropy would
+		// derive ours from it, and change its node ID if it's another node's.
+		if id, err = g.after(g.last); err != nil {
+			return id, err
+		}
+	case same:
+		id = g.last
+		if err = g.increment(&id); err != nil {
+			return id, err
+		}
+	default:
+		if id, err = g.fresh(ms, frac); err != nil {
+			return id, err
+		}
+	}
+
+	end := id
+	if n > 1 {
+		if end, err = g.add(id, uint64(n-1)); err != nil {
+			return id, err
+		}
+	}
+
+	g.last, g.observed = end, false
+	return id, nil
+}
+
+// fresh returns a ULID with the given timestamp and fraction, and random
+// entropy but for the reserved bits.
+func (g *Generator) fresh(ms uint64, frac uint16) (id ULID, err error) {
+	if err = id.SetTime(ms); err != nil {
+		return id, err
+	}
+
+	if _, err = io.ReadFull(g.entropy, id[6:]); err != nil {
+		return id, err
+	}
+
+	if g.reserved > 0 {
+		prefix := g.node
+		if g.nanos {
+			prefix = uint64(frac)
+		}
+
+		var e uint80
+		e.SetBytes(id[6:])
+		e.SetTop(g.reserved, prefix)
+		e.AppendTo(id[6:])
+	}
+
+	return id, nil
+}
+
+// after returns a ULID with fresh entropy that sorts after the observed ULID
+// last: within its timestamp if the reserved bits allow it, or in the next one.
+func (g *Generator) after(last ULID) (id ULID, err error) {
+	ms, prefix := last.Time(), g.node
+	if g.nanos {
+		prefix = uint64(last.frac())
+	}
+
+	switch lastPrefix := last.Node(g.reserved); {
+	case prefix > lastPrefix:
+		return g.fresh(ms, last.frac())
+	case prefix < lastPrefix:
+		return g.fresh(ms+1, 0)
+	}
+
+	// The space above last's entropy, below the reserved bits.
+	var e, space uint80
+	e.SetBytes(last[6:])
+	space = uint80{Hi: ^e.Hi, Lo: ^e.Lo}
+	space.SetTop(g.reserved, 0)
+	if space.IsZero() {
+		return g.fresh(ms+1, 0)
+	}
+
+	// Add 1 plus a random number below the largest power of two within the
+	// space, which is at least half of it.
+	var r uint80
+	if _, err = io.ReadFull(g.entropy, id[6:]); err != nil {
+		return id, err
+	}
+	r.SetBytes(id[6:])
+	if k := space.bitLen() - 1; k >= 64 {
+		r.Hi &= 1<<(k-64) - 1
+	} else {
+		r.Hi, r.Lo = 0, r.Lo&(1<<k-1)
+	}
+
+	var carry uint64
+	e.Lo, carry = bits.Add64(e.Lo, r.Lo, 0)
+	e.Hi += r.Hi + uint16(carry)
+	e.Add(1)
+
+	_ = id.SetTime(ms)
+	e.AppendTo(id[6:])
+	return id, nil
+}
+
+// tick combines a timestamp with its sub-millisecond fraction, if enabled.
+func (g *Generator) tick(ms uint64, frac uint16) uint64 {
+	if g.nanos {
+		return ms<<12 | uint64(frac)
+	}
+	return ms
+}
+
+// increment adds a random number in [1, 2^32] to the entropy of id, leaving
+// the reserved high bits untouched.
+func (g *Generator) increment(id *ULID) error {
+	if _, err := io.ReadFull(g.entropy, g.rand[:]); err != nil {
+		return err
+	}
+
+	var e uint80
+	e.SetBytes(id[6:])
+	prefix := e.Top(g.reserved)
+	if e.Add(1+uint64(binary.BigEndian.Uint32(g.rand[:]))) || e.Top(g.reserved) != prefix {
+		return ErrMonotonicOverflow
+	}
+	e.AppendTo(id[6:])
+
+	return nil
+}
+
+// frac returns the sub-millisecond fraction stored in the top 12 bits of the
+// entropy by a Generator configured with WithSubMillisecond.
+func (id ULID) frac() uint16 {
+	return uint16(id[6])<<4 | uint16(id[7])>>4
+}
+
+// TimeNanos returns the Unix time in nanoseconds encoded in a ULID generated
+// with sub-millisecond precision (see WithSubMillisecond), rounded down to
+// the resolution of the fraction. For other ULIDs, everything below the
+// millisecond is meaningless. Times after the year 2554 overflow the result.
+func (id ULID) TimeNanos() uint64 {
+	return id.Time()*1e6 + (uint64(id.frac())*1e6+1<<12-1)>>12
+}
+
+// Node returns the node ID stored in the given number of high bits of the
+// entropy by a Generator configured with WithNode. The number of bits must be
+// at most 64.
+func (id ULID) Node(bits uint) uint64 {
+	var e uint80
+	e.SetBytes(id[6:])
+	return e.Top(bits)
+}
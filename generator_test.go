@@ -0,0 +1,465 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestGenerator(t *testing.T) {
+	t.Parallel()
+
+	g, err := ulid.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			prev := g.MustNew()
+			for j := 0; j < 1024; j++ {
+				next := g.MustNew()
+				if prev.Compare(next) >= 0 {
+					errs <- fmt.Errorf("%s >= %s", prev, next)
+					return
+				}
+				prev = next
+			}
+			errs <- nil
+		}()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGeneratorOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, opt := range []ulid.GeneratorOption{
+		ulid.WithClock(nil),
+		ulid.WithEntropy(nil),
+	} {
+		if _, err := ulid.NewGenerator(opt); err == nil {
+			t.Error("expected error for invalid option")
+		}
+	}
+}
+
+func TestGeneratorClockRegression(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	g, err := ulid.NewGenerator(ulid.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := g.MustNew()
+	clock.Add(-time.Second)
+	second := g.MustNew()
+
+	if second.Compare(first) <= 0 {
+		t.Fatalf("%s <= %s after clock regression", second, first)
+	}
+	if got, want := second.Time(), first.Time(); got != want {
+		t.Errorf("got time %d after clock regression, want %d", got, want)
+	}
+
+	clock.Add(2 * time.Second)
+	if got, want := g.MustNew().Time(), ulid.Timestamp(clock.Now()); got != want {
+		t.Errorf("got time %d after clock recovery, want %d", got, want)
+	}
+}
+
+func TestGeneratorOverflow(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		opts []ulid.GeneratorOption
+	}{
+		{"default", nil},
+		{"sub-millisecond", []ulid.GeneratorOption{ulid.WithSubMillisecond()}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &fakeClock{t: time.Unix(1e6, 0)}
+			opts := append(tc.opts,
+				ulid.WithClock(clock.Now),
+				ulid.WithEntropy(io.MultiReader(
+					bytes.NewReader(bytes.Repeat([]byte{0xFF}, 14)), // Entropy for first ULID and increment
+					crand.Reader, // Following random entropy
+				)),
+			)
+			g, err := ulid.NewGenerator(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			first := g.MustNew()
+			if _, err := g.New(); err != ulid.ErrMonotonicOverflow {
+				t.Fatalf("got err %v, want %v", err, ulid.ErrMonotonicOverflow)
+			}
+
+			clock.Add(time.Millisecond)
+			if next := g.MustNew(); next.Compare(first) <= 0 {
+				t.Fatalf("%s <= %s after overflow", next, first)
+			}
+		})
+	}
+}
+
+func TestGeneratorSubMillisecond(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1e6, 0)
+	clock := &fakeClock{t: start}
+	g, err := ulid.NewGenerator(ulid.WithClock(clock.Now), ulid.WithSubMillisecond())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const step = 300 * time.Nanosecond
+	var prev ulid.ULID
+	for i := 0; i < 10000; i++ {
+		now := clock.Now()
+		id := g.MustNew()
+
+		if got, want := id.Time(), ulid.Timestamp(now); got != want {
+			t.Fatalf("got time %d, want %d", got, want)
+		}
+
+		diff := time.Duration(uint64(now.UnixNano()) - id.TimeNanos())
+		if diff < 0 || diff >= 245*time.Nanosecond {
+			t.Fatalf("TimeNanos is %v off from %v", diff, now)
+		}
+
+		if id.Compare(prev) <= 0 {
+			t.Fatalf("%s <= %s", id, prev)
+		}
+		prev = id
+
+		clock.Add(step)
+	}
+}
+
+func TestGeneratorSubMillisecondOrder(t *testing.T) {
+	t.Parallel()
+
+	// Two generators, as in separate processes, generating a ULID each within
+	// the same millisecond, must be ordered by their sub-millisecond times.
+	start := time.Unix(1e6, 0)
+	for i := time.Duration(0); i < time.Millisecond-time.Microsecond; i += 997 * time.Nanosecond {
+		a, _ := ulid.NewGenerator(ulid.WithSubMillisecond(), ulid.WithClock(func() time.Time { return start.Add(i) }))
+		b, _ := ulid.NewGenerator(ulid.WithSubMillisecond(), ulid.WithClock(func() time.Time { return start.Add(i + time.Microsecond) }))
+		if x, y := a.MustNew(), b.MustNew(); x.Compare(y) >= 0 {
+			t.Fatalf("at +%v: %s >= %s", i, x, y)
+		}
+	}
+}
+
+func TestTimeNanos(t *testing.T) {
+	t.Parallel()
+
+	for frac := 0; frac < 1<<12; frac++ {
+		id := ulid.MustNew(1e6, nil)
+		id[6], id[7] = byte(frac>>4), byte(frac<<4)
+
+		ns := id.TimeNanos()
+		if got, want := ns/1e6, id.Time(); got != want {
+			t.Fatalf("frac %d: got ms %d, want %d", frac, got, want)
+		}
+
+		// Converting back must yield the same fraction.
+		if got := int(ns%1e6) << 12 / 1e6; got != frac {
+			t.Fatalf("frac %d: round-trips to %d", frac, got)
+		}
+	}
+}
+
+func TestGeneratorNode(t *testing.T) {
+	t.Parallel()
+
+	// Two generators sharing clock and entropy streams, as the worst case of
+	// nodes starting at the same time with the same seed.
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	var gens []*ulid.Generator
+	for _, node := range []uint64{0x2A, 0x3FF} {
+		g, err := ulid.NewGenerator(
+			ulid.WithClock(clock.Now),
+			ulid.WithEntropy(ulid.Seeded([]byte("seed"))),
+			ulid.WithNode(node, 10),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gens = append(gens, g)
+	}
+
+	seen := map[ulid.ULID]bool{}
+	prev := make([]ulid.ULID, len(gens))
+	for i := 0; i < 1000; i++ {
+		if i%100 == 0 {
+			clock.Add(time.Millisecond)
+		}
+		for j, g := range gens {
+			id := g.MustNew()
+			if seen[id] {
+				t.Fatalf("duplicate ULID %s", id)
+			}
+			seen[id] = true
+
+			if got, want := id.Node(10), []uint64{0x2A, 0x3FF}[j]; got != want {
+				t.Fatalf("%s: got node %#x, want %#x", id, got, want)
+			}
+			if id.Compare(prev[j]) <= 0 {
+				t.Fatalf("%s <= %s", id, prev[j])
+			}
+			prev[j] = id
+		}
+	}
+}
+
+func TestGeneratorNodeOverflow(t *testing.T) {
+	t.Parallel()
+
+	g, err := ulid.NewGenerator(
+		ulid.WithClock(func() time.Time { return time.Unix(1e6, 0) }),
+		ulid.WithEntropy(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 14))),
+		ulid.WithNode(0, 32),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := g.MustNew(); id.Node(32) != 0 {
+		t.Fatalf("%s: got node %#x, want 0", id, id.Node(32))
+	}
+
+	// Incrementing must not carry into the node bits.
+	if _, err := g.New(); err != ulid.ErrMonotonicOverflow {
+		t.Fatalf("got err %v, want %v", err, ulid.ErrMonotonicOverflow)
+	}
+}
+
+func TestGeneratorNodeOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range [][]ulid.GeneratorOption{
+		{ulid.WithNode(0, 0)},
+		{ulid.WithNode(0, 33)},
+		{ulid.WithNode(16, 4)},
+		{ulid.WithNode(1, 4), ulid.WithSubMillisecond()},
+	} {
+		if _, err := ulid.NewGenerator(opts...); err == nil {
+			t.Errorf("expected error for options %v", opts)
+		}
+	}
+
+	if _, err := ulid.NewGenerator(ulid.WithNode(1<<32-1, 32)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNode(t *testing.T) {
+	t.Parallel()
+
+	id := ulid.MustNew(0, bytes.NewReader([]byte{0xAB, 0xCD, 0xEF, 0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD}))
+	for _, tc := range []struct {
+		bits uint
+		want uint64
+	}{
+		{0, 0},
+		{4, 0xA},
+		{16, 0xABCD},
+		{20, 0xABCDE},
+		{64, 0xABCDEF0123456789},
+	} {
+		if got := id.Node(tc.bits); got != tc.want {
+			t.Errorf("Node(%d): got %#x, want %#x", tc.bits, got, tc.want)
+		}
+	}
+}
+
+func TestGeneratorObserve(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		optsA      []ulid.GeneratorOption
+		optsB      []ulid.GeneratorOption
+		nodeA      uint64
+		nodeB      uint64
+		checkNodes bool
+	}{
+		{name: "default"},
+		{name: "sub-millisecond", optsA: []ulid.GeneratorOption{ulid.WithSubMillisecond()}, optsB: []ulid.GeneratorOption{ulid.WithSubMillisecond()}},
+		{name: "lower node", optsA: []ulid.GeneratorOption{ulid.WithNode(1, 8)}, optsB: []ulid.GeneratorOption{ulid.WithNode(2, 8)}, nodeA: 1, nodeB: 2, checkNodes: true},
+		{name: "higher node", optsA: []ulid.GeneratorOption{ulid.WithNode(2, 8)}, optsB: []ulid.GeneratorOption{ulid.WithNode(1, 8)}, nodeA: 2, nodeB: 1, checkNodes: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Node A's clock is a second ahead of node B's.
+			clockA := &fakeClock{t: time.Unix(1e6, 0).Add(time.Second)}
+			clockB := &fakeClock{t: time.Unix(1e6, 0)}
+			a, err := ulid.NewGenerator(append(tc.optsA, ulid.WithClock(clockA.Now))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ulid.NewGenerator(append(tc.optsB, ulid.WithClock(clockB.Now))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Ping-pong messages between the nodes, each reacting to the
+			// other's last ULID.
+			cause := a.MustNew()
+			for i := 0; i < 100; i++ {
+				clockA.Add(time.Microsecond)
+				clockB.Add(time.Microsecond)
+
+				b.Observe(cause)
+				effect := b.MustNew()
+				if effect.Compare(cause) <= 0 {
+					t.Fatalf("B's %s <= A's cause %s", effect, cause)
+				}
+				if tc.checkNodes && effect.Node(8) != tc.nodeB {
+					t.Fatalf("B's %s has node %d", effect, effect.Node(8))
+				}
+
+				a.Observe(effect)
+				cause = a.MustNew()
+				if cause.Compare(effect) <= 0 {
+					t.Fatalf("A's %s <= B's cause %s", cause, effect)
+				}
+				if tc.checkNodes && cause.Node(8) != tc.nodeA {
+					t.Fatalf("A's %s has node %d", cause, cause.Node(8))
+				}
+			}
+
+			// B stays close to A's clock rather than drifting further.
+			if got, limit := cause.Time(), ulid.Timestamp(clockA.Now())+100; got > limit {
+				t.Errorf("timestamps drifted to %d, beyond %d", got, limit)
+			}
+		})
+	}
+}
+
+func TestGeneratorObserveFanOut(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		opts []ulid.GeneratorOption
+	}{
+		{"default", nil},
+		{"sub-millisecond", []ulid.GeneratorOption{ulid.WithSubMillisecond()}},
+		{"same node", []ulid.GeneratorOption{ulid.WithNode(1, 8)}},
+	} {
+		clock := &fakeClock{t: time.Unix(1e6, 0)}
+		opts := append(tc.opts, ulid.WithClock(clock.Now))
+
+		for i := 0; i < 100; i++ {
+			source, _ := ulid.NewGenerator(opts...)
+			a, _ := ulid.NewGenerator(opts...)
+			b, _ := ulid.NewGenerator(opts...)
+
+			// Both nodes react to the same ULID within its millisecond.
+			cause := source.MustNew()
+			a.Observe(cause)
+			b.Observe(cause)
+			effectA, effectB := a.MustNew(), b.MustNew()
+
+			for _, effect := range []ulid.ULID{effectA, effectB} {
+				if effect.Compare(cause) <= 0 {
+					t.Fatalf("%s: %s <= cause %s", tc.name, effect, cause)
+				}
+				// An increment of the cause's entropy would leave its high
+				// bytes unchanged.
+				if effect.Time() == cause.Time() && bytes.Equal(effect[6:11], cause[6:11]) {
+					t.Fatalf("%s: %s derived from cause %s", tc.name, effect, cause)
+				}
+			}
+
+			if effectA == effectB {
+				t.Fatalf("%s: both nodes generated %s", tc.name, effectA)
+			}
+		}
+	}
+}
+
+func TestGeneratorObserveOlder(t *testing.T) {
+	t.Parallel()
+
+	g, _ := ulid.NewGenerator()
+	last := g.MustNew()
+	g.Observe(ulid.MustNew(0, nil))
+	if next := g.MustNew(); next.Compare(last) <= 0 {
+		t.Fatalf("%s <= %s after observing an older ULID", next, last)
+	}
+}
+
+func BenchmarkGenerator(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		opts []ulid.GeneratorOption
+	}{
+		{"Default", nil},
+		{"SubMillisecond", []ulid.GeneratorOption{ulid.WithSubMillisecond()}},
+	} {
+		g, _ := ulid.NewGenerator(tc.opts...)
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(16)
+			for i := 0; i < b.N; i++ {
+				_ = g.MustNew()
+			}
+		})
+	}
+}
+
+// fakeClock is a manually advanced clock, safe for concurrent use.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
@@ -0,0 +1,133 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "encoding/binary"
+
+// Conversions from other sortable ID schemes. Each maps the source timestamp
+// to the ULID timestamp and the remaining bits, most significant first, to the
+// entropy, so converted ULIDs sort in the same order as their sources.
+
+const (
+	// KSUIDEpoch is the KSUID epoch, in seconds since the Unix epoch.
+	KSUIDEpoch = 1400000000
+
+	// TwitterEpoch is the Snowflake epoch used by Twitter, in milliseconds
+	// since the Unix epoch.
+	TwitterEpoch = 1288834974657
+
+	// uuidEpoch is the Gregorian epoch of UUID timestamps, 1582-10-15, in 100ns
+	// ticks before the Unix epoch.
+	uuidEpoch = 0x01B21DD213814000
+)
+
+// FromKSUID converts a 20 byte KSUID. Its timestamp has a resolution of one
+// second, and the first 10 bytes of its 16 byte payload become the entropy.
+// Since the rest of the payload is dropped, distinct KSUIDs with the same
+// timestamp and payload prefix convert to the same ULID. Order is preserved,
+// except that such KSUIDs compare equal.
+func FromKSUID(ksuid []byte) (id ULID, err error) {
+	if len(ksuid) != 20 {
+		return id, ErrDataSize
+	}
+
+	secs := uint64(binary.BigEndian.Uint32(ksuid)) + KSUIDEpoch
+	if err := id.SetTime(secs * 1000); err != nil {
+		return id, err
+	}
+
+	copy(id[6:], ksuid[4:14])
+	return id, nil
+}
+
+// FromXID converts a 12 byte xid. Its timestamp has a resolution of one
+// second, and its 8 byte machine ID, process ID and counter become the most
+// significant entropy bytes, followed by 2 zero bytes. The conversion is
+// lossless and order preserving.
+func FromXID(xid []byte) (id ULID, err error) {
+	if len(xid) != 12 {
+		return id, ErrDataSize
+	}
+
+	secs := uint64(binary.BigEndian.Uint32(xid))
+	if err := id.SetTime(secs * 1000); err != nil {
+		return id, err
+	}
+
+	copy(id[6:], xid[4:12])
+	return id, nil
+}
+
+// FromSnowflake converts a Snowflake ID whose timestamp is in milliseconds
+// since the given epoch, such as TwitterEpoch. The low 22 bits of the ID,
+// holding the machine ID and sequence number, become the most significant
+// entropy bits, followed by zero bits. The conversion is lossless and order
+// preserving.
+func FromSnowflake(snowflake uint64, epoch uint64) (id ULID, err error) {
+	if err := id.SetTime(snowflake>>22 + epoch); err != nil {
+		return id, err
+	}
+
+	var e uint80
+	e.SetTop(22, snowflake&(1<<22-1))
+	e.AppendTo(id[6:])
+	return id, nil
+}
+
+// FromUUID converts a 16 byte version 1 or version 6 UUID. Other versions
+// return ErrUUIDVersion, and UUIDs from before the Unix epoch ErrSmallTime.
+//
+// The 100ns timestamp is split into milliseconds and a 14 bit remainder. The
+// entropy holds the remainder, the 14 bit clock sequence and the 48 bit node,
+// followed by 4 zero bits. The conversion is lossless and preserves the order
+// of the UUIDs by timestamp, clock sequence and node.
+func FromUUID(uuid []byte) (id ULID, err error) {
+	if len(uuid) != 16 {
+		return id, ErrDataSize
+	}
+
+	var ticks uint64
+	switch uuid[6] >> 4 {
+	case 1:
+		ticks = uint64(binary.BigEndian.Uint16(uuid[6:])&0x0fff)<<48 |
+			uint64(binary.BigEndian.Uint16(uuid[4:]))<<32 |
+			uint64(binary.BigEndian.Uint32(uuid[0:]))
+	case 6:
+		ticks = uint64(binary.BigEndian.Uint32(uuid[0:]))<<28 |
+			uint64(binary.BigEndian.Uint16(uuid[4:]))<<12 |
+			uint64(binary.BigEndian.Uint16(uuid[6:])&0x0fff)
+	default:
+		return id, ErrUUIDVersion
+	}
+
+	if ticks < uuidEpoch {
+		return id, ErrSmallTime
+	}
+	ticks -= uuidEpoch
+
+	if err := id.SetTime(ticks / 10000); err != nil {
+		return id, err
+	}
+
+	var (
+		rem  = ticks % 10000
+		seq  = uint64(binary.BigEndian.Uint16(uuid[8:]) & 0x3fff)
+		node = uint64(binary.BigEndian.Uint16(uuid[10:]))<<32 | uint64(binary.BigEndian.Uint32(uuid[12:]))
+		e    uint80
+	)
+	e.SetTop(28, rem<<14|seq)
+	e.Lo |= node << 4
+	e.AppendTo(id[6:])
+	return id, nil
+}
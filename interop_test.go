@@ -0,0 +1,178 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestFromKSUID(t *testing.T) {
+	t.Parallel()
+
+	ksuid := mustHex(t, "0ee2c4a0"+"000102030405060708090a0b0c0d0e0f")
+	id, err := ulid.FromKSUID(ksuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := id.Time(), uint64(0x0ee2c4a0+ulid.KSUIDEpoch)*1000; got != want {
+		t.Errorf("Time: got %d, want %d", got, want)
+	}
+
+	if got, want := id.Entropy(), ksuid[4:14]; !bytes.Equal(got, want) {
+		t.Errorf("Entropy: got %x, want %x", got, want)
+	}
+
+	if _, err := ulid.FromKSUID(ksuid[:19]); !errors.Is(err, ulid.ErrDataSize) {
+		t.Errorf("FromKSUID(short): got err %v, want %v", err, ulid.ErrDataSize)
+	}
+}
+
+func TestFromXID(t *testing.T) {
+	t.Parallel()
+
+	xid := mustHex(t, "4d88e15b60f486e428412dc9")
+	id, err := ulid.FromXID(xid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := id.Time(), uint64(1300816219000); got != want {
+		t.Errorf("Time: got %d, want %d", got, want)
+	}
+
+	if got, want := id.Entropy(), append(xid[4:12:12], 0, 0); !bytes.Equal(got, want) {
+		t.Errorf("Entropy: got %x, want %x", got, want)
+	}
+
+	if _, err := ulid.FromXID(xid[:11]); !errors.Is(err, ulid.ErrDataSize) {
+		t.Errorf("FromXID(short): got err %v, want %v", err, ulid.ErrDataSize)
+	}
+}
+
+func TestFromSnowflake(t *testing.T) {
+	t.Parallel()
+
+	const ms = 1000
+	snowflake := uint64(ms)<<22 | 0x3ff<<12 | 0xabc
+	id, err := ulid.FromSnowflake(snowflake, ulid.TwitterEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := id.Time(), uint64(ulid.TwitterEpoch+ms); got != want {
+		t.Errorf("Time: got %d, want %d", got, want)
+	}
+
+	// 0x3ffabc << 2 = 0xffeaf0
+	if got, want := id.Entropy(), []byte{0xff, 0xea, 0xf0, 0, 0, 0, 0, 0, 0, 0}; !bytes.Equal(got, want) {
+		t.Errorf("Entropy: got %x, want %x", got, want)
+	}
+}
+
+func TestFromUUID(t *testing.T) {
+	t.Parallel()
+
+	// Examples from RFC 9562, appendices A.1 and A.5.
+	for _, tc := range []struct {
+		name string
+		uuid string
+	}{
+		{"v1", "c232ab00941411ecb3c89f6bdeced846"},
+		{"v6", "1ec9414c232a6b00b3c89f6bdeced846"},
+	} {
+		id, err := ulid.FromUUID(mustHex(t, tc.uuid))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		if got, want := id.Time(), uint64(1645557742000); got != want {
+			t.Errorf("%s: Time: got %d, want %d", tc.name, got, want)
+		}
+
+		// 14 bit remainder 0, 14 bit clock sequence 0x33c8, 48 bit node and
+		// 4 zero bits.
+		if got, want := id.Entropy(), mustHex(t, "00033c89f6bdeced8460"); !bytes.Equal(got, want) {
+			t.Errorf("%s: Entropy: got %x, want %x", tc.name, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		uuid string
+		err  error
+	}{
+		{"short", "c232ab00941411ecb3c89f6bdeced8", ulid.ErrDataSize},
+		{"v4", "919108f752d133205bacf847db4148a8", ulid.ErrUUIDVersion},
+		{"v7", "017f22e279b07cc398c4dc0c0c07398f", ulid.ErrUUIDVersion},
+		{"before 1970", "00000000000010008000000000000000", ulid.ErrSmallTime},
+	} {
+		if _, err := ulid.FromUUID(mustHex(t, tc.uuid)); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got err %v, want %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestFromUUIDOrder(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+	uuids := make([][]byte, 1000)
+	for i := range uuids {
+		// Few distinct timestamps, so that clock sequences and nodes matter.
+		ticks := uint64(0x01B21DD213814000) + uint64(rng.Int63n(64))*5000
+
+		u := make([]byte, 16)
+		rng.Read(u[8:])
+		binary.BigEndian.PutUint32(u[0:], uint32(ticks>>28))
+		binary.BigEndian.PutUint16(u[4:], uint16(ticks>>12))
+		binary.BigEndian.PutUint16(u[6:], 0x6000|uint16(ticks&0x0fff))
+		u[8] = u[8]&0x3f | 0x80
+		uuids[i] = u
+	}
+
+	// Version 6 UUIDs sort by timestamp, clock sequence and node, so their
+	// byte order must match that of the converted ULIDs.
+	sort.Slice(uuids, func(i, j int) bool { return bytes.Compare(uuids[i], uuids[j]) < 0 })
+
+	ids := make([]ulid.ULID, len(uuids))
+	for i, u := range uuids {
+		id, err := ulid.FromUUID(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+
+	if !ulid.IsSorted(ids) {
+		t.Error("converted ULIDs are not sorted")
+	}
+}
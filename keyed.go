@@ -0,0 +1,179 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+)
+
+// A KeyedGenerator generates ULIDs that are strictly increasing per key, such
+// as a tenant or partition, with an independent Generator for each key. ULIDs
+// for different keys are generated in parallel, each under its own lock.
+//
+// To bound memory, it keeps the Generators of at most a fixed number of keys,
+// evicting the least recently used idle ones. Keys in use are never evicted,
+// so the capacity may be exceeded while more keys are in use at once. ULIDs
+// generated for a key after its eviction still sort after those generated
+// before, with fresh entropy: keys are hashed into a fixed number of buckets,
+// and new Generators start after the timestamp of the greatest ULID evicted
+// from their bucket. So while keys are evicted and recreated within the same
+// millisecond, ULIDs of keys sharing a bucket are pulled ahead of the clock by
+// a millisecond per eviction, until it catches up.
+//
+// A KeyedGenerator is safe for concurrent use.
+type KeyedGenerator struct {
+	mu       sync.Mutex
+	opts     []GeneratorOption
+	capacity int
+	lru      *list.List // Of *keyedGenerator, most recently used first.
+	keys     map[string]*list.Element
+	evicted  []uint64 // Per bucket, the timestamp after the greatest evicted ULID.
+}
+
+// keyedBuckets is the number of buckets of evicted timestamps of a
+// KeyedGenerator.
+const keyedBuckets = 4096
+
+// bucket returns the bucket of key, hashed with FNV-1a.
+func bucket(key string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return int(h % keyedBuckets)
+}
+
+type keyedGenerator struct {
+	key  string
+	g    *Generator
+	busy int // Number of calls to New in flight.
+}
+
+// NewKeyedGenerator returns a KeyedGenerator that keeps the Generators of at
+// most capacity keys, each configured with the given options. Since the
+// Generators of evicted keys are discarded, WithStateStore isn't supported.
+// An entropy source configured with WithEntropy is shared by all keys, so it
+// must be safe for concurrent use.
+func NewKeyedGenerator(capacity int, opts ...GeneratorOption) (*KeyedGenerator, error) {
+	if capacity < 1 {
+		return nil, errors.New("ulid: keyed generator capacity must be positive")
+	}
+
+	// Validate the options once, rather than on every new key.
+	g, err := NewGenerator(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.store != nil {
+		return nil, errors.New("ulid: keyed generators don't support state stores")
+	}
+
+	return &KeyedGenerator{
+		opts:     opts,
+		capacity: capacity,
+		lru:      list.New(),
+		keys:     make(map[string]*list.Element),
+		evicted:  make([]uint64, keyedBuckets),
+	}, nil
+}
+
+// New returns a ULID with the current time, which is greater than all the
+// ULIDs previously generated by k for the same key. It returns the same errors
+// as Generator.New.
+func (k *KeyedGenerator) New(key string) (id ULID, err error) {
+	kg, err := k.acquire(key)
+	if err != nil {
+		return id, err
+	}
+
+	id, err = kg.g.New()
+
+	k.mu.Lock()
+	kg.busy--
+	k.evict()
+	k.mu.Unlock()
+
+	return id, err
+}
+
+// MustNew is a convenience function equivalent to New that panics on failure
+// instead of returning an error.
+func (k *KeyedGenerator) MustNew(key string) ULID {
+	id, err := k.New(key)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Len returns the number of keys whose Generators k currently keeps.
+func (k *KeyedGenerator) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lru.Len()
+}
+
+// acquire returns the Generator of key, marked busy, creating it and evicting
+// idle ones as needed.
+func (k *KeyedGenerator) acquire(key string) (*keyedGenerator, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if e, ok := k.keys[key]; ok {
+		k.lru.MoveToFront(e)
+		kg := e.Value.(*keyedGenerator)
+		kg.busy++
+		return kg, nil
+	}
+
+	g, err := NewGenerator(k.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	g.floor = k.evicted[bucket(key)]
+
+	kg := &keyedGenerator{key: key, g: g, busy: 1}
+	k.keys[key] = k.lru.PushFront(kg)
+	k.evict()
+
+	return kg, nil
+}
+
+// evict drops the least recently used idle Generators while k keeps more than
+// its capacity.
+func (k *KeyedGenerator) evict() {
+	for e := k.lru.Back(); e != nil && k.lru.Len() > k.capacity; {
+		prev := e.Prev()
+
+		if kg := e.Value.(*keyedGenerator); kg.busy == 0 {
+			k.lru.Remove(e)
+			delete(k.keys, kg.key)
+
+			kg.g.mu.Lock()
+			after := kg.g.last.Time() + 1
+			kg.g.mu.Unlock()
+
+			if b := bucket(kg.key); after > k.evicted[b] {
+				k.evicted[b] = after
+			}
+		}
+
+		e = prev
+	}
+}
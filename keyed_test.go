@@ -0,0 +1,164 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestKeyedGenerator(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	k, err := ulid.NewKeyedGenerator(4, ulid.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Many more keys than capacity, all within the same millisecond, so that
+	// keys get evicted and recreated while their previous ULIDs are current.
+	const keys, n = 16, 200
+	var wg sync.WaitGroup
+	errs := make(chan error, keys)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("tenant-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var prev ulid.ULID
+			for j := 0; j < n; j++ {
+				id, err := k.New(key)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if prev.Compare(id) >= 0 {
+					errs <- fmt.Errorf("%s: %v >= %v", key, prev, id)
+					return
+				}
+				prev = id
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if got, want := k.Len(), 4; got != want {
+		t.Errorf("Len: got %d, want %d", got, want)
+	}
+}
+
+func TestKeyedGeneratorEviction(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	k, err := ulid.NewKeyedGenerator(1, ulid.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1 := k.MustNew("a")
+	b := k.MustNew("b") // Evicts a.
+	a2 := k.MustNew("a")
+
+	if a1.Compare(a2) >= 0 {
+		t.Errorf("a1 %v >= a2 %v", a1, a2)
+	}
+
+	now := ulid.Timestamp(clock.Now())
+	for _, tc := range []struct {
+		name string
+		id   ulid.ULID
+		want uint64
+	}{
+		{"a1", a1, now},
+		{"b", b, now},
+		{"a2", a2, now + 1}, // After a1, with fresh entropy.
+	} {
+		if got := tc.id.Time(); got != tc.want {
+			t.Errorf("%s.Time(): got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// Keys created once the clock advanced use it again.
+	clock.Add(time.Second)
+	if got, want := k.MustNew("c").Time(), ulid.Timestamp(clock.Now()); got != want {
+		t.Errorf("c.Time(): got %d, want %d", got, want)
+	}
+}
+
+func TestKeyedGeneratorEvictionDrift(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	k, err := ulid.NewKeyedGenerator(2, ulid.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Evict and recreate many keys within one millisecond. Each key is
+	// evicted twice, so its own ULIDs drift by two milliseconds; keys sharing
+	// a bucket add a few more, but the drift must not grow with the number of
+	// keys, as it would with a single floor for all of them.
+	const keys, rounds, maxDrift = 1000, 3, 16
+	var (
+		last  = make(map[string]ulid.ULID)
+		now   = ulid.Timestamp(clock.Now())
+		drift uint64
+	)
+	for i := 0; i < keys*rounds; i++ {
+		key := fmt.Sprintf("tenant-%d", i%keys)
+		id := k.MustNew(key)
+
+		if prev, ok := last[key]; ok && prev.Compare(id) >= 0 {
+			t.Fatalf("%s: %v >= %v", key, prev, id)
+		}
+		last[key] = id
+
+		if d := id.Time() - now; d > drift {
+			drift = d
+		}
+	}
+
+	if drift > maxDrift {
+		t.Errorf("got drift of %d ms, want at most %d", drift, maxDrift)
+	}
+}
+
+func TestNewKeyedGeneratorErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		capacity int
+		opts     []ulid.GeneratorOption
+	}{
+		{"zero capacity", 0, nil},
+		{"state store", 1, []ulid.GeneratorOption{ulid.WithStateStore(&memStore{}, 0)}},
+		{"invalid option", 1, []ulid.GeneratorOption{ulid.WithClock(nil)}},
+	} {
+		if _, err := ulid.NewKeyedGenerator(tc.capacity, tc.opts...); err == nil {
+			t.Errorf("%s: got no error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "bytes"
+
+// Helpers for using ULIDs as keys in ordered key-value stores, such as Badger,
+// Bolt or Pebble. Since ULIDs sort by time and their binary form sorts the same
+// way, keys made of a common prefix followed by a binary ULID sort by time
+// within that prefix. Prefixes of different key spaces mustn't be prefixes of
+// one another, e.g. they should have a fixed length or end in a separator;
+// otherwise their key ranges overlap.
+
+// Key returns a composite key of prefix followed by the binary ULID. Keys with
+// the same prefix sort in the same order as their ULIDs.
+func Key(prefix []byte, id ULID) []byte {
+	key := make([]byte, len(prefix)+len(id))
+	copy(key, prefix)
+	copy(key[len(prefix):], id[:])
+	return key
+}
+
+// KeyID returns the ULID of a composite key made by Key with the given prefix.
+// It returns ErrDataSize if the key doesn't consist of the prefix followed by a
+// binary ULID.
+func KeyID(prefix, key []byte) (id ULID, err error) {
+	if len(key) != len(prefix)+len(id) || !bytes.HasPrefix(key, prefix) {
+		return id, ErrDataSize
+	}
+	copy(id[:], key[len(prefix):])
+	return id, nil
+}
+
+// KeyRange returns the half-open key range [start, end) of composite keys with
+// the given prefix whose ULID timestamps are within the half-open range
+// [from, to), in Unix milliseconds. If to is larger than MaxTime, end is
+// PrefixEnd(prefix), which is nil for an unbounded range.
+func KeyRange(prefix []byte, from, to uint64) (start, end []byte) {
+	if from > maxTime {
+		// Past the largest possible key with the prefix.
+		start = append(Key(prefix, MaxAt(maxTime)), 0)
+	} else {
+		start = Key(prefix, MinAt(from))
+	}
+
+	if to > maxTime {
+		end = PrefixEnd(prefix)
+	} else {
+		end = Key(prefix, MinAt(to))
+	}
+
+	if end != nil && bytes.Compare(start, end) > 0 {
+		end = start
+	}
+
+	return start, end
+}
+
+// PrefixEnd returns the smallest key larger than all keys with the given
+// prefix, for use as the exclusive end of a prefix scan. It returns nil if
+// there is no such key, i.e. if the prefix is empty or consists of 0xFF bytes
+// only, meaning the scan is unbounded.
+func PrefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xFF {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+	return nil
+}
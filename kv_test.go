@@ -0,0 +1,126 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	prefix := []byte("users/")
+	id := ulid.Make()
+	key := ulid.Key(prefix, id)
+
+	if got, want := key, append([]byte("users/"), id.Bytes()...); !bytes.Equal(got, want) {
+		t.Errorf("Key: got %x, want %x", got, want)
+	}
+
+	got, err := ulid.KeyID(prefix, key)
+	if err != nil || got != id {
+		t.Errorf("KeyID: got %v, %v, want %v, nil", got, err, id)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		prefix []byte
+		key    []byte
+	}{
+		{"other prefix", []byte("posts/"), key},
+		{"short", prefix, key[:len(key)-1]},
+		{"long", prefix, append(key, 0)},
+	} {
+		if _, err := ulid.KeyID(tc.prefix, tc.key); !errors.Is(err, ulid.ErrDataSize) {
+			t.Errorf("KeyID(%s): got err %v, want %v", tc.name, err, ulid.ErrDataSize)
+		}
+	}
+}
+
+func TestKeyRange(t *testing.T) {
+	t.Parallel()
+
+	ids := sortedIDs(1000)
+	prefixes := [][]byte{[]byte("a/"), []byte("b/"), []byte("b\xff"), []byte("c/")}
+
+	// Simulate an ordered key-value store holding all IDs under all prefixes.
+	var keys [][]byte
+	for _, p := range prefixes {
+		for _, id := range ids {
+			keys = append(keys, ulid.Key(p, id))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	scan := func(start, end []byte) (out [][]byte) {
+		for _, k := range keys {
+			if bytes.Compare(k, start) >= 0 && (end == nil || bytes.Compare(k, end) < 0) {
+				out = append(out, k)
+			}
+		}
+		return out
+	}
+
+	last := ids[len(ids)-1].Time()
+	for _, r := range []struct{ from, to uint64 }{
+		{0, 0},
+		{0, 1},
+		{0, last / 2},
+		{last / 4, last / 2},
+		{last / 2, last},
+		{last / 2, last + 1},
+		{last, 0},
+		{0, ulid.MaxTime()},
+		{0, ulid.MaxTime() + 1},
+		{ulid.MaxTime() + 1, ulid.MaxTime() + 2},
+	} {
+		want := ulid.Between(ids, r.from, r.to)
+		for _, p := range prefixes {
+			start, end := ulid.KeyRange(p, r.from, r.to)
+			got := scan(start, end)
+			if len(got) != len(want) {
+				t.Errorf("KeyRange(%q, %d, %d): got %d keys, want %d", p, r.from, r.to, len(got), len(want))
+				continue
+			}
+			for i, k := range got {
+				if id, err := ulid.KeyID(p, k); err != nil || id != want[i] {
+					t.Errorf("KeyRange(%q, %d, %d): key %d: got %v, %v, want %v", p, r.from, r.to, i, id, err, want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		prefix, want []byte
+	}{
+		{nil, nil},
+		{[]byte{0xFF, 0xFF}, nil},
+		{[]byte("a"), []byte("b")},
+		{[]byte{'a', 0xFF}, []byte("b")},
+		{[]byte{'a', 0xFE, 0xFF}, []byte{'a', 0xFF}},
+	} {
+		if got := ulid.PrefixEnd(tc.prefix); !bytes.Equal(got, tc.want) || (got == nil) != (tc.want == nil) {
+			t.Errorf("PrefixEnd(%x): got %x, want %x", tc.prefix, got, tc.want)
+		}
+	}
+}
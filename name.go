@@ -0,0 +1,42 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// NewFromName returns a ULID with the given Unix milliseconds timestamp and
+// entropy derived from the namespace and name, so that the same inputs always
+// yield the same ULID. This is similar in spirit to name-based UUIDs (version
+// 3 and 5), except that the ULID keeps the caller-supplied timestamp and thus
+// remains time-sortable.
+//
+// The entropy is the first 10 bytes of the HMAC-SHA256 of the name, keyed by
+// the namespace. Names are only as hard to guess as the inputs, so these ULIDs
+// shouldn't be relied upon to be unpredictable.
+//
+// ErrBigTime is returned when passing a timestamp bigger than MaxTime.
+func NewFromName(ms uint64, namespace ULID, name []byte) (id ULID, err error) {
+	if err = id.SetTime(ms); err != nil {
+		return id, err
+	}
+
+	mac := hmac.New(sha256.New, namespace[:])
+	mac.Write(name)
+	copy(id[6:], mac.Sum(nil))
+
+	return id, nil
+}
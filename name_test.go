@@ -0,0 +1,62 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestNewFromName(t *testing.T) {
+	t.Parallel()
+
+	ns := ulid.MustParse("01HK153X004C5WRPCYNR0G33VN")
+	ms := ulid.Timestamp(ns.Timestamp())
+
+	id, err := ulid.NewFromName(ms, ns, []byte("orders/1234"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pinned, so that re-imports keep yielding the same ULIDs.
+	if got, want := id.String(), "01HK153X00PAPW3DJX1DEY91YK"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if got, want := id.Time(), ms; got != want {
+		t.Errorf("got time %d, want %d", got, want)
+	}
+
+	for _, tc := range []struct {
+		name string
+		ns   ulid.ULID
+		in   string
+	}{
+		{"other name", ns, "orders/1235"},
+		{"other namespace", ulid.Zero, "orders/1234"},
+	} {
+		other, err := ulid.NewFromName(ms, tc.ns, []byte(tc.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other == id {
+			t.Errorf("%s: got the same ULID %s", tc.name, other)
+		}
+	}
+
+	if _, err := ulid.NewFromName(ulid.MaxTime()+1, ns, nil); err != ulid.ErrBigTime {
+		t.Errorf("got err %v, want %v", err, ulid.ErrBigTime)
+	}
+}
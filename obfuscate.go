@@ -0,0 +1,48 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "crypto/aes"
+
+// Obfuscate returns an opaque ULID that hides the timestamp and entropy of
+// id, for exposure in public places like URLs. It encrypts the 128 bits of the
+// ULID with AES, using the given key, which must be 16, 24 or 32 bytes long.
+// Every 128 bit value is a valid ULID, so the result encodes to a regular
+// 26 character string, but it doesn't sort by time nor carry a meaningful
+// timestamp.
+//
+// The transform is deterministic, so the same id and key always yield the same
+// result, and reversible with Deobfuscate and the same key.
+func Obfuscate(id ULID, key []byte) (out ULID, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return out, err
+	}
+
+	block.Encrypt(out[:], id[:])
+	return out, nil
+}
+
+// Deobfuscate reverses Obfuscate, returning the original ULID. The key must be
+// the one used to obfuscate it. A wrong key yields a wrong ULID rather than an
+// error.
+func Deobfuscate(id ULID, key []byte) (out ULID, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return out, err
+	}
+
+	block.Decrypt(out[:], id[:])
+	return out, nil
+}
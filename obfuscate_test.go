@@ -0,0 +1,78 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/quick"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestObfuscate(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{0x42}, 16)
+
+	prop := func(id ulid.ULID) bool {
+		pub, err := ulid.Obfuscate(id, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The public form must be a valid, strictly parseable ULID.
+		s := pub.String()
+		if len(s) != ulid.EncodedSize || ulid.MustParseStrict(s) != pub {
+			return false
+		}
+
+		back, err := ulid.Deobfuscate(pub, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return back == id && pub != id
+	}
+
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1e4}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObfuscateHidesTime(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+	a, _ := ulid.Obfuscate(ulid.MustNew(1000, nil), key)
+	b, _ := ulid.Obfuscate(ulid.MustNew(1001, nil), key)
+	if a.Time() == b.Time() || a.Time()+1 == b.Time() {
+		t.Errorf("obfuscated timestamps leak order: %d, %d", a.Time(), b.Time())
+	}
+
+	other, _ := ulid.Deobfuscate(a, bytes.Repeat([]byte{0x43}, 32))
+	if other == ulid.MustNew(1000, nil) {
+		t.Error("deobfuscated with the wrong key")
+	}
+}
+
+func TestObfuscateKeySize(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ulid.Obfuscate(ulid.Zero, []byte("short")); err == nil {
+		t.Error("Obfuscate: expected error for bad key size")
+	}
+	if _, err := ulid.Deobfuscate(ulid.Zero, nil); err == nil {
+		t.Error("Deobfuscate: expected error for bad key size")
+	}
+}
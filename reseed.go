@@ -0,0 +1,140 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// DefaultReseedInterval is the number of bytes a ReseedingEntropy yields
+// before reseeding, unless configured otherwise.
+const DefaultReseedInterval = 1 << 20
+
+// pidCheckInterval is the number of bytes a ReseedingEntropy yields between
+// checks of the process ID.
+const pidCheckInterval = 4096
+
+// Reseeding returns a fast source of entropy backed by a pseudo-random
+// generator which is seeded with 32 bytes read from the given seed source.
+// The seed source should be cryptographically secure; if it's nil,
+// crypto/rand.Reader is used. On Go 1.22 and later, the generator is ChaCha8
+// from math/rand/v2, which is itself cryptographically strong.
+//
+// The generator is reseeded after yielding n bytes, and when it notices that
+// the process ID changed, so that processes cloned from the same process image
+// don't keep sharing an entropy stream. Since checking the process ID costs a
+// system call, it's only checked every pidCheckInterval bytes, which bounds
+// the output a clone may share with its origin. Go programs can't fork without
+// exec, so this only matters for processes restored from a snapshot. Passing
+// n <= 0 results in DefaultReseedInterval.
+//
+// The returned type isn't safe for concurrent use.
+func Reseeding(seed io.Reader, n int) *ReseedingEntropy {
+	if seed == nil {
+		seed = crand.Reader
+	}
+
+	if n <= 0 {
+		n = DefaultReseedInterval
+	}
+
+	return &ReseedingEntropy{seed: seed, interval: n}
+}
+
+// ReseedingEntropy is an opaque type that provides periodically reseeded
+// pseudo-random entropy.
+type ReseedingEntropy struct {
+	seed      io.Reader
+	src       uint64Source
+	interval  int
+	left      int // Bytes until the next reseed.
+	pid       int
+	unchecked int // Bytes until the next check of the process ID.
+}
+
+type uint64Source interface{ Uint64() uint64 }
+
+// Read implements the io.Reader interface. It returns an error only if
+// reading a new seed fails.
+func (r *ReseedingEntropy) Read(p []byte) (n int, err error) {
+	var buf [8]byte
+	for n < len(p) {
+		if err = r.check(); err != nil {
+			return n, err
+		}
+
+		binary.LittleEndian.PutUint64(buf[:], r.src.Uint64())
+		c := copy(p[n:], buf[:])
+		r.left -= c
+		r.unchecked -= c
+		n += c
+	}
+
+	return n, nil
+}
+
+// Int63n returns a uniform random number in [0, n). It panics if n <= 0.
+// MonotonicEntropy uses it to compute random increments without going
+// through Read. If reseeding fails, it keeps using the current seed until the
+// next attempt.
+func (r *ReseedingEntropy) Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("ulid: invalid argument to Int63n")
+	}
+
+	max := uint64(1<<63 - 1 - (1<<63)%uint64(n))
+	for {
+		if err := r.check(); err != nil && r.src == nil {
+			panic(err)
+		}
+
+		v := r.src.Uint64() >> 1
+		r.left -= 8
+		r.unchecked -= 8
+		if v <= max {
+			return int64(v % uint64(n))
+		}
+	}
+}
+
+// check reseeds the generator if it yielded its interval of bytes, or if the
+// process ID changed, checking it every pidCheckInterval bytes.
+func (r *ReseedingEntropy) check() error {
+	if r.unchecked <= 0 {
+		if pid := os.Getpid(); pid != r.pid {
+			r.pid, r.left = pid, 0
+		}
+		r.unchecked = pidCheckInterval
+	}
+
+	if r.left <= 0 {
+		return r.reseed()
+	}
+
+	return nil
+}
+
+func (r *ReseedingEntropy) reseed() error {
+	var seed [32]byte
+	if _, err := io.ReadFull(r.seed, seed[:]); err != nil {
+		return err
+	}
+
+	r.src = newSource(seed)
+	r.left = r.interval
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	crand "crypto/rand"
+	"testing"
+)
+
+// seedCounter counts the seeds read from it.
+type seedCounter struct{ reads int }
+
+func (s *seedCounter) Read(p []byte) (int, error) {
+	s.reads++
+	return crand.Read(p)
+}
+
+func TestReseedingPIDChange(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		read func(r *ReseedingEntropy)
+	}{
+		{"Read", func(r *ReseedingEntropy) { _, _ = r.Read(make([]byte, 8)) }},
+		{"Int63n", func(r *ReseedingEntropy) { _ = r.Int63n(1 << 62) }},
+	} {
+		var seed seedCounter
+		r := Reseeding(&seed, 0)
+
+		tc.read(r)
+		if got, want := seed.reads, 1; got != want {
+			t.Fatalf("%s: got %d seed reads initially, want %d", tc.name, got, want)
+		}
+
+		// Without a process ID change, crossing a check doesn't reseed.
+		for i := 0; i < pidCheckInterval/8; i++ {
+			tc.read(r)
+		}
+		if got, want := seed.reads, 1; got != want {
+			t.Fatalf("%s: got %d seed reads without a process ID change, want %d", tc.name, got, want)
+		}
+
+		// Simulate running in a cloned process. The change is noticed by the
+		// next check, at most pidCheckInterval bytes later.
+		r.pid = -1
+		for i := 0; i < pidCheckInterval/8 && seed.reads == 1; i++ {
+			tc.read(r)
+		}
+		if got, want := seed.reads, 2; got != want {
+			t.Fatalf("%s: got %d seed reads after a process ID change, want %d", tc.name, got, want)
+		}
+
+		if r.pid == -1 {
+			t.Errorf("%s: process ID not updated after reseeding", tc.name)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestReseeding(t *testing.T) {
+	t.Parallel()
+
+	a, b := ulid.Reseeding(nil, 0), ulid.Reseeding(nil, 0)
+	pa, pb := make([]byte, 64), make([]byte, 64)
+	if _, err := io.ReadFull(a, pa); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(b, pb); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(pa, pb) {
+		t.Error("independent sources yielded the same stream")
+	}
+}
+
+func TestReseedingInterval(t *testing.T) {
+	t.Parallel()
+
+	seed := &countingReader{}
+	entropy := ulid.Reseeding(seed, 32)
+
+	if _, err := io.ReadFull(entropy, make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := seed.n, byte(32); got != want {
+		t.Fatalf("got %d seed bytes read, want %d", got, want)
+	}
+
+	if _, err := io.ReadFull(entropy, make([]byte, 100)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := seed.n, byte(32*5); got != want {
+		t.Fatalf("got %d seed bytes read, want %d", got, want)
+	}
+}
+
+func TestReseedingError(t *testing.T) {
+	t.Parallel()
+
+	errBroken := errors.New("broken")
+	entropy := ulid.Reseeding(io.MultiReader(
+		bytes.NewReader(make([]byte, 32)),
+		&errReader{errBroken},
+	), 16)
+
+	if _, err := ulid.New(0, entropy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ulid.New(0, entropy); err != errBroken {
+		t.Errorf("got err %v, want %v", err, errBroken)
+	}
+}
+
+func TestReseedingInt63n(t *testing.T) {
+	t.Parallel()
+
+	entropy := ulid.Reseeding(crand.Reader, 64)
+	for _, n := range []int64{1, 2, 3, 1000, 1<<62 + 1, 1<<63 - 1} {
+		for i := 0; i < 100; i++ {
+			if v := entropy.Int63n(n); v < 0 || v >= n {
+				t.Fatalf("Int63n(%d) = %d", n, v)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Int63n(0): expected panic")
+		}
+	}()
+	entropy.Int63n(0)
+}
+
+func TestReseedingMonotonic(t *testing.T) {
+	t.Parallel()
+
+	entropy := ulid.Monotonic(ulid.Reseeding(nil, 1024), 0)
+
+	var prev ulid.ULID
+	for i := 0; i < 10000; i++ {
+		next, err := ulid.New(123, entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev.Compare(next) >= 0 {
+			t.Fatalf("prev: %v >= next: %v", prev, next)
+		}
+		prev = next
+	}
+}
+
+func BenchmarkReseeding(b *testing.B) {
+	entropy := ulid.Reseeding(nil, 0)
+	b.ReportAllocs()
+	b.SetBytes(16)
+	for i := 0; i < b.N; i++ {
+		_ = ulid.MustNew(123, entropy)
+	}
+}
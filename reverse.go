@@ -0,0 +1,78 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+// Reversed is the bitwise complement of a ULID. Reversed ULIDs sort in the
+// opposite order of their ULIDs, both in binary and text form, so forward
+// scans over keys made of them yield the newest ULIDs first.
+type Reversed [16]byte
+
+// Reverse returns the bitwise complement of the ULID.
+func (id ULID) Reverse() (r Reversed) {
+	for i := range id {
+		r[i] = ^id[i]
+	}
+	return r
+}
+
+// ULID returns the original ULID.
+func (r Reversed) ULID() (id ULID) {
+	for i := range r {
+		id[i] = ^r[i]
+	}
+	return id
+}
+
+// ParseReversed parses an encoded reversed ULID, as returned by
+// Reversed.String. Unlike Parse, it always validates the input, like
+// ParseStrict.
+func ParseReversed(s string) (r Reversed, err error) {
+	return r, r.UnmarshalText([]byte(s))
+}
+
+// Bytes returns bytes slice representation of the reversed ULID.
+func (r Reversed) Bytes() []byte {
+	return r[:]
+}
+
+// String returns the reversed ULID in the same base 32 encoding as
+// ULID.String, which sorts in the same order as the binary form.
+func (r Reversed) String() string {
+	return ULID(r).String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface by returning
+// the string encoded reversed ULID.
+func (r Reversed) MarshalText() ([]byte, error) {
+	return ULID(r).MarshalText()
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface by parsing
+// the data as a string encoded reversed ULID.
+func (r *Reversed) UnmarshalText(v []byte) error {
+	return parse(v, true, (*ULID)(r))
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface by
+// returning the reversed ULID as a byte slice.
+func (r Reversed) MarshalBinary() ([]byte, error) {
+	return ULID(r).MarshalBinary()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface by
+// copying the passed data and converting it to a reversed ULID. ErrDataSize
+// is returned if the data length is different from ULID length.
+func (r *Reversed) UnmarshalBinary(data []byte) error {
+	return (*ULID)(r).UnmarshalBinary(data)
+}
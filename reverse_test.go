@@ -0,0 +1,92 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestReverse(t *testing.T) {
+	t.Parallel()
+
+	id := ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	r := id.Reverse()
+
+	if got, want := r.String(), "7YN70WAJHC564V77GG8SPFTGN4"; got != want {
+		t.Errorf("String: got %q, want %q", got, want)
+	}
+
+	if got := r.ULID(); got != id {
+		t.Errorf("ULID: got %v, want %v", got, id)
+	}
+
+	parsed, err := ulid.ParseReversed(r.String())
+	if err != nil || parsed != r {
+		t.Errorf("ParseReversed: got %v, %v, want %v, nil", parsed, err, r)
+	}
+
+	text, err := r.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromText ulid.Reversed
+	if err := fromText.UnmarshalText(text); err != nil || fromText != r {
+		t.Errorf("UnmarshalText: got %v, %v, want %v, nil", fromText, err, r)
+	}
+
+	bin, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBin ulid.Reversed
+	if err := fromBin.UnmarshalBinary(bin); err != nil || fromBin != r {
+		t.Errorf("UnmarshalBinary: got %v, %v, want %v, nil", fromBin, err, r)
+	}
+
+	for _, tc := range []struct {
+		in  string
+		err error
+	}{
+		{"7YN70WAJHC564V77GG8SPFTGN", ulid.ErrDataSize},
+		{"7YN70WAJHC564V77GG8SPFTGNU", ulid.ErrInvalidCharacters},
+		{"8YN70WAJHC564V77GG8SPFTGN4", ulid.ErrOverflow},
+	} {
+		if _, err := ulid.ParseReversed(tc.in); !errors.Is(err, tc.err) {
+			t.Errorf("ParseReversed(%q): got err %v, want %v", tc.in, err, tc.err)
+		}
+	}
+}
+
+func TestReverseOrder(t *testing.T) {
+	t.Parallel()
+
+	ids := sortedIDs(1000)
+	rs := make([]ulid.Reversed, len(ids))
+	for i, id := range ids {
+		rs[i] = id.Reverse()
+	}
+
+	if !sort.SliceIsSorted(rs, func(i, j int) bool { return bytes.Compare(rs[j][:], rs[i][:]) < 0 }) {
+		t.Error("reversed ULIDs don't sort in descending binary order")
+	}
+
+	if !sort.SliceIsSorted(rs, func(i, j int) bool { return rs[j].String() < rs[i].String() }) {
+		t.Error("reversed ULIDs don't sort in descending text order")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// SignedSize is the length of a signed ULID, as returned by Sign: an encoded
+// ULID followed by 16 characters of signature.
+const SignedSize = EncodedSize + 16
+
+// Sign returns the string encoded ULID followed by a signature, so that ULIDs
+// handed out as resource handles can be checked for forgery without a lookup.
+// The signature is the HMAC-SHA256 of the ULID's binary form keyed by key,
+// truncated to 80 bits and encoded with the same base 32 alphabet as ULIDs.
+//
+// Keys should be at least 32 bytes of secure random data.
+func Sign(id ULID, key []byte) string {
+	signed := make([]byte, SignedSize)
+	_ = id.MarshalTextTo(signed[:EncodedSize])
+	encodeSignature(signed[EncodedSize:], signature(id, key))
+	return string(signed)
+}
+
+// Verify parses a ULID signed by Sign and checks its signature against each
+// of the given keys in constant time, so that keys can be rotated by signing
+// with the new key while still verifying with both.
+//
+// ErrDataSize is returned if len(signed) is different from SignedSize.
+// Invalid encodings return a *ParseError, like ParseStrict. ErrSignature is
+// returned if no key matches.
+func Verify(signed string, keys ...[]byte) (id ULID, err error) {
+	if len(signed) != SignedSize {
+		return id, ErrDataSize
+	}
+
+	if err = parse([]byte(signed[:EncodedSize]), true, &id); err != nil {
+		if perr, ok := err.(*ParseError); ok {
+			perr.Input = signed
+		}
+		return id, err
+	}
+
+	var sig [10]byte
+	if i := decodeSignature(sig[:], signed[EncodedSize:]); i >= 0 {
+		return id, &ParseError{Input: signed, Offset: EncodedSize + i, Err: ErrInvalidCharacters}
+	}
+
+	for _, key := range keys {
+		if want := signature(id, key); hmac.Equal(sig[:], want[:]) {
+			return id, nil
+		}
+	}
+
+	return id, ErrSignature
+}
+
+func signature(id ULID, key []byte) (sig [10]byte) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(id[:])
+	copy(sig[:], mac.Sum(nil))
+	return sig
+}
+
+// encodeSignature encodes the 10 byte signature into 16 characters, five bits
+// per character, most significant bits first.
+func encodeSignature(dst []byte, sig [10]byte) {
+	for i := 0; i < 2; i++ {
+		b := sig[i*5 : i*5+5]
+		d := dst[i*8 : i*8+8]
+		d[0] = Encoding[b[0]>>3]
+		d[1] = Encoding[(b[0]&7)<<2|b[1]>>6]
+		d[2] = Encoding[(b[1]>>1)&31]
+		d[3] = Encoding[(b[1]&1)<<4|b[2]>>4]
+		d[4] = Encoding[(b[2]&15)<<1|b[3]>>7]
+		d[5] = Encoding[(b[3]>>2)&31]
+		d[6] = Encoding[(b[3]&3)<<3|b[4]>>5]
+		d[7] = Encoding[b[4]&31]
+	}
+}
+
+// decodeSignature reverses encodeSignature, returning the offset of the first
+// invalid character, or -1 if all are valid.
+func decodeSignature(dst []byte, src string) int {
+	var c [16]byte
+	for i := range c {
+		if c[i] = dec[src[i]]; c[i] == 0xFF {
+			return i
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		d := c[i*8 : i*8+8]
+		b := dst[i*5 : i*5+5]
+		b[0] = d[0]<<3 | d[1]>>2
+		b[1] = d[1]<<6 | d[2]<<1 | d[3]>>4
+		b[2] = d[3]<<4 | d[4]>>1
+		b[3] = d[4]<<7 | d[5]<<2 | d[6]>>3
+		b[4] = d[6]<<5 | d[7]
+	}
+
+	return -1
+}
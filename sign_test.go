@@ -0,0 +1,108 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	prop := func(id ulid.ULID) bool {
+		signed := ulid.Sign(id, key)
+		if len(signed) != ulid.SignedSize || !strings.HasPrefix(signed, id.String()) {
+			return false
+		}
+
+		got, err := ulid.Verify(signed, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Signatures are case insensitive, like ULIDs.
+		lower, err := ulid.Verify(strings.ToLower(signed), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return got == id && lower == id
+	}
+
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1e4}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := make([]byte, 32), make([]byte, 32)
+	_, _ = crand.Read(oldKey)
+	_, _ = crand.Read(newKey)
+
+	id := ulid.MustNew(ulid.Now(), crand.Reader)
+	signed := ulid.Sign(id, oldKey)
+
+	// Pair the signature with another ULID, and flip its last bit.
+	forgedID := ulid.Sign(ulid.ULID{}, oldKey)[:ulid.EncodedSize] + signed[ulid.EncodedSize:]
+	forgedSig := signed[:ulid.SignedSize-1] + string(ulid.Encoding[strings.IndexByte(ulid.Encoding, signed[ulid.SignedSize-1])^1])
+
+	for _, tc := range []struct {
+		name   string
+		signed string
+		keys   [][]byte
+		err    error
+	}{
+		{"valid", signed, [][]byte{oldKey}, nil},
+		{"rotated", signed, [][]byte{newKey, oldKey}, nil},
+		{"wrong key", signed, [][]byte{newKey}, ulid.ErrSignature},
+		{"no keys", signed, nil, ulid.ErrSignature},
+		{"forged id", forgedID, [][]byte{oldKey}, ulid.ErrSignature},
+		{"forged signature", forgedSig, [][]byte{oldKey}, ulid.ErrSignature},
+		{"unsigned", id.String(), [][]byte{oldKey}, ulid.ErrDataSize},
+		{"bad characters", signed[:ulid.SignedSize-1] + "U", [][]byte{oldKey}, ulid.ErrInvalidCharacters},
+		{"overflow", "8" + signed[1:], [][]byte{oldKey}, ulid.ErrOverflow},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ulid.Verify(tc.signed, tc.keys...)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got err %v, want %v", err, tc.err)
+			}
+			if err == nil && got != id {
+				t.Errorf("got ULID %s, want %s", got, id)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	signed := ulid.Sign(ulid.Make(), key)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = ulid.Verify(signed, key)
+	}
+}
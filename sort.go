@@ -0,0 +1,54 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "sort"
+
+// Sort sorts ids in increasing order, as defined by Compare.
+func Sort(ids []ULID) {
+	sort.Sort(byULID(ids))
+}
+
+// IsSorted reports whether ids is sorted in increasing order, as defined by
+// Compare.
+func IsSorted(ids []ULID) bool {
+	return sort.IsSorted(byULID(ids))
+}
+
+// SearchTime searches for the first ULID in ids whose timestamp is at least
+// ms, using binary search over the time prefix. It returns len(ids) if there
+// is no such ULID. The slice must be sorted in increasing order.
+func SearchTime(ids []ULID, ms uint64) int {
+	return sort.Search(len(ids), func(i int) bool {
+		return ids[i].Time() >= ms
+	})
+}
+
+// Between returns the sub-slice of ids whose timestamps are within the
+// half-open range [from, to), in Unix milliseconds. The returned slice shares
+// its backing array with ids. The slice must be sorted in increasing order.
+func Between(ids []ULID, from, to uint64) []ULID {
+	if to <= from {
+		return ids[:0]
+	}
+	i := SearchTime(ids, from)
+	j := i + SearchTime(ids[i:], to)
+	return ids[i:j]
+}
+
+type byULID []ULID
+
+func (s byULID) Len() int           { return len(s) }
+func (s byULID) Less(i, j int) bool { return s[i].Compare(s[j]) < 0 }
+func (s byULID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
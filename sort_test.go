@@ -0,0 +1,130 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+
+	prop := func(ids []ulid.ULID) bool {
+		ulid.Sort(ids)
+		for i := 1; i < len(ids); i++ {
+			if ids[i-1].Compare(ids[i]) > 0 {
+				return false
+			}
+		}
+		return ulid.IsSorted(ids)
+	}
+
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1e3}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []ulid.ULID{ulid.MustNew(2, nil), ulid.MustNew(1, nil)}
+	if ulid.IsSorted(ids) {
+		t.Errorf("IsSorted(%v): got true, want false", ids)
+	}
+}
+
+func TestSearchTime(t *testing.T) {
+	t.Parallel()
+
+	ids := sortedIDs(1000)
+	for _, id := range ids {
+		ms := id.Time()
+		i := ulid.SearchTime(ids, ms)
+		if i == len(ids) || ids[i].Time() != ms {
+			t.Fatalf("SearchTime(%d): got index %d", ms, i)
+		}
+		if i > 0 && ids[i-1].Time() >= ms {
+			t.Fatalf("SearchTime(%d): index %d isn't the first match", ms, i)
+		}
+	}
+
+	if got, want := ulid.SearchTime(ids, ulid.MaxTime()), len(ids); got != want {
+		t.Errorf("SearchTime(MaxTime): got %d, want %d", got, want)
+	}
+
+	if got, want := ulid.SearchTime(nil, 0), 0; got != want {
+		t.Errorf("SearchTime(nil): got %d, want %d", got, want)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	t.Parallel()
+
+	ids := sortedIDs(1000)
+	for _, tc := range []struct {
+		from, to uint64
+	}{
+		{0, ulid.MaxTime()},
+		{ids[0].Time(), ids[len(ids)-1].Time()},
+		{ids[10].Time(), ids[500].Time()},
+		{ids[10].Time(), ids[10].Time() + 1},
+		{ids[500].Time(), ids[10].Time()},
+		{ulid.MaxTime(), ulid.MaxTime()},
+	} {
+		got := ulid.Between(ids, tc.from, tc.to)
+
+		var want []ulid.ULID
+		for _, id := range ids {
+			if ms := id.Time(); ms >= tc.from && ms < tc.to {
+				want = append(want, id)
+			}
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("Between(%d, %d): got %d ids, want %d", tc.from, tc.to, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("Between(%d, %d): got %s at %d, want %s", tc.from, tc.to, got[i], i, want[i])
+			}
+		}
+	}
+
+	// The result must alias the input.
+	sub := ulid.Between(ids, ids[1].Time(), ulid.MaxTime())
+	if len(sub) > 0 && &sub[0] != &ids[len(ids)-len(sub)] {
+		t.Error("Between: result doesn't share the input's backing array")
+	}
+}
+
+func sortedIDs(n int) []ulid.ULID {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ids := make([]ulid.ULID, n)
+	for i := range ids {
+		// Narrow time range to get plenty of timestamp collisions.
+		ids[i] = ulid.MustNew(uint64(rng.Int63n(int64(n/4+1))), rng)
+	}
+	ulid.Sort(ids)
+	return ids
+}
+
+func BenchmarkSearchTime(b *testing.B) {
+	ids := sortedIDs(1 << 16)
+	ms := ids[len(ids)/2].Time()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ulid.SearchTime(ids, ms)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// Version bytes of the state encodings.
+const (
+	monotonicStateV1 = 1
+	generatorStateV1 = 1
+	generatorStateV2 = 2 // Adds the timestamp floor.
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface by encoding
+// the timestamp and entropy of the last MonotonicRead, so that a later
+// process can continue the monotonic sequence with UnmarshalBinary.
+func (m *MonotonicEntropy) MarshalBinary() ([]byte, error) {
+	state := make([]byte, 19)
+	state[0] = monotonicStateV1
+	binary.BigEndian.PutUint64(state[1:], m.ms)
+	m.entropy.AppendTo(state[9:])
+	return state, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface by
+// restoring the state encoded by MarshalBinary. ErrInvalidState is returned if
+// the data is malformed.
+func (m *MonotonicEntropy) UnmarshalBinary(data []byte) error {
+	if len(data) != 19 || data[0] != monotonicStateV1 {
+		return ErrInvalidState
+	}
+	m.ms = binary.BigEndian.Uint64(data[1:])
+	m.entropy.SetBytes(data[9:])
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface by encoding
+// the last ULID generated by g, and the timestamp it must continue from, if
+// any.
+func (g *Generator) MarshalBinary() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.marshal(g.floor), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface by
+// restoring the state encoded by MarshalBinary, so that g continues after
+// the last ULID it encodes, even if the clock went back in the meantime. State
+// older than g's own is ignored. ErrInvalidState is returned if the data is
+// malformed.
+func (g *Generator) UnmarshalBinary(data []byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.unmarshal(data)
+}
+
+func (g *Generator) marshal(floor uint64) []byte {
+	state := make([]byte, 25)
+	state[0] = generatorStateV2
+	copy(state[1:], g.last[:])
+	binary.BigEndian.PutUint64(state[17:], floor)
+	return state
+}
+
+func (g *Generator) unmarshal(data []byte) error {
+	var floor uint64
+	switch {
+	case len(data) == 17 && data[0] == generatorStateV1:
+	case len(data) == 25 && data[0] == generatorStateV2:
+		floor = binary.BigEndian.Uint64(data[17:])
+	default:
+		return ErrInvalidState
+	}
+
+	var last ULID
+	copy(last[:], data[1:17])
+	if last.Compare(g.last) > 0 {
+		g.last = last
+	}
+
+	if floor > g.floor {
+		g.floor = floor
+	}
+
+	return nil
+}
+
+// A StateStore persists the state of a Generator across process restarts.
+type StateStore interface {
+	// Load returns the last saved state, or nil if there is none.
+	Load() ([]byte, error)
+
+	// Save durably replaces the saved state.
+	Save(state []byte) error
+}
+
+// Checkpoint saves the state of g to its StateStore, if it has one.
+func (g *Generator) Checkpoint() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.store == nil {
+		return nil
+	}
+
+	if err := g.load(); err != nil {
+		return err
+	}
+
+	return g.save(g.now())
+}
+
+func (g *Generator) load() error {
+	if g.store == nil || g.loaded {
+		return nil
+	}
+
+	state, err := g.store.Load()
+	if err != nil {
+		return err
+	}
+
+	if state != nil {
+		if err = g.unmarshal(state); err != nil {
+			return err
+		}
+	}
+
+	g.loaded = true
+	return nil
+}
+
+func (g *Generator) save(t time.Time) error {
+	// Until the next save, g generates ULIDs with timestamps up to the end of
+	// the checkpoint interval, so a restarted Generator must continue after it.
+	floor := g.floor
+	if g.interval > 0 {
+		ahead := g.codec.Timestamp(t.Add(g.interval))
+		if last := g.last.Time(); last > ahead {
+			ahead = last
+		}
+		if ahead+1 > floor {
+			floor = ahead + 1
+		}
+	}
+
+	if err := g.store.Save(g.marshal(floor)); err != nil {
+		return err
+	}
+	g.saved = t
+	return nil
+}
+
+// FileStore is a StateStore that keeps the state in a file.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a FileStore that keeps the state in the file at path.
+// The file's directory must exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load implements the StateStore interface. It returns nil if the file
+// doesn't exist.
+func (s *FileStore) Load() ([]byte, error) {
+	state, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return state, err
+}
+
+// Save implements the StateStore interface. It replaces the file atomically,
+// by writing and syncing a temporary file in the same directory, renaming it
+// over the file, and syncing the directory.
+func (s *FileStore) Save(state []byte) (err error) {
+	dir := filepath.Dir(s.path)
+	f, err := ioutil.TempFile(dir, filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(state); err != nil {
+		return err
+	}
+
+	if err = f.Sync(); err != nil {
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(f.Name(), s.path); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir makes a rename within dir durable. Directories can't be opened for
+// syncing on Windows.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	if err = d.Sync(); err != nil {
+		_ = d.Close()
+		return err
+	}
+
+	return d.Close()
+}
@@ -0,0 +1,255 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestMonotonicState(t *testing.T) {
+	t.Parallel()
+
+	entropy := ulid.Monotonic(ulid.Reseeding(nil, 0), 0)
+	prev := ulid.MustNew(123, entropy)
+
+	state, err := entropy.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh process continuing within the same millisecond.
+	restored := ulid.Monotonic(ulid.Reseeding(nil, 0), 0)
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		next := ulid.MustNew(123, restored)
+		if next.Compare(prev) <= 0 {
+			t.Fatalf("%s <= %s after restoring state", next, prev)
+		}
+		prev = next
+	}
+
+	for _, data := range [][]byte{nil, state[:len(state)-1], append([]byte{0}, state[1:]...)} {
+		if err := restored.UnmarshalBinary(data); err != ulid.ErrInvalidState {
+			t.Errorf("UnmarshalBinary(%x): got err %v, want %v", data, err, ulid.ErrInvalidState)
+		}
+	}
+}
+
+func TestGeneratorState(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	g, _ := ulid.NewGenerator(ulid.WithClock(clock.Now))
+	last := g.MustNew()
+
+	state, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted process whose clock stepped back.
+	clock.Add(-time.Minute)
+	restored, _ := ulid.NewGenerator(ulid.WithClock(clock.Now))
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	if next := restored.MustNew(); next.Compare(last) <= 0 {
+		t.Fatalf("%s <= %s after restoring state", next, last)
+	}
+
+	// Older state must not rewind the generator.
+	newer := restored.MustNew()
+	if err := restored.UnmarshalBinary(state); err != nil {
+		t.Fatal(err)
+	}
+	if next := restored.MustNew(); next.Compare(newer) <= 0 {
+		t.Fatalf("%s <= %s after restoring older state", next, newer)
+	}
+
+	if err := restored.UnmarshalBinary(state[1:]); err != ulid.ErrInvalidState {
+		t.Errorf("got err %v, want %v", err, ulid.ErrInvalidState)
+	}
+}
+
+func TestGeneratorStateStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "ulid.state")
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+
+	g, err := ulid.NewGenerator(
+		ulid.WithClock(clock.Now),
+		ulid.WithStateStore(ulid.NewFileStore(path), time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last ulid.ULID
+	for i := 0; i < 10; i++ {
+		last = g.MustNew()
+	}
+	if err := g.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart with the clock stepped back.
+	clock.Add(-time.Hour)
+	g, err = ulid.NewGenerator(
+		ulid.WithClock(clock.Now),
+		ulid.WithStateStore(ulid.NewFileStore(path), time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := g.MustNew(); next.Compare(last) <= 0 {
+		t.Fatalf("%s <= %s after restart", next, last)
+	}
+
+	// No temporary files must be left behind.
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d files, want 1", len(files))
+	}
+}
+
+func TestGeneratorStateStoreInterval(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	store := &memStore{}
+	g, _ := ulid.NewGenerator(ulid.WithClock(clock.Now), ulid.WithStateStore(store, time.Second))
+
+	for _, d := range []time.Duration{0, 500 * time.Millisecond, 499 * time.Millisecond, time.Millisecond, time.Millisecond} {
+		clock.Add(d)
+		_ = g.MustNew()
+	}
+	if got, want := store.saves, 2; got != want {
+		t.Errorf("got %d saves, want %d", got, want)
+	}
+
+	errBroken := errors.New("broken")
+	store.err = errBroken
+	clock.Add(time.Second)
+	if _, err := g.New(); err != errBroken {
+		t.Errorf("got err %v, want %v", err, errBroken)
+	}
+}
+
+func TestGeneratorStateStoreLoadError(t *testing.T) {
+	t.Parallel()
+
+	errBroken := errors.New("broken")
+	g, _ := ulid.NewGenerator(ulid.WithStateStore(&memStore{err: errBroken}, 0))
+	if _, err := g.New(); err != errBroken {
+		t.Errorf("got err %v, want %v", err, errBroken)
+	}
+
+	g, _ = ulid.NewGenerator(ulid.WithStateStore(&memStore{state: []byte("garbage")}, 0))
+	if _, err := g.New(); err != ulid.ErrInvalidState {
+		t.Errorf("got err %v, want %v", err, ulid.ErrInvalidState)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state")
+	s := ulid.NewFileStore(path)
+
+	if state, err := s.Load(); state != nil || err != nil {
+		t.Fatalf("got state %x, err %v, want none", state, err)
+	}
+
+	for _, want := range []string{"first", "second"} {
+		if err := s.Save([]byte(want)); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.Load(); string(got) != want || err != nil {
+			t.Fatalf("got state %q, err %v, want %q", got, err, want)
+		}
+	}
+
+	bad := ulid.NewFileStore(filepath.Join(path+".missing", "state"))
+	if err := bad.Save(nil); !os.IsNotExist(err) {
+		t.Errorf("got err %v, want a not-exist error", err)
+	}
+}
+
+type memStore struct {
+	state []byte
+	saves int
+	err   error
+}
+
+func (s *memStore) Load() ([]byte, error) { return s.state, s.err }
+
+func (s *memStore) Save(state []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.state = state
+	s.saves++
+	return nil
+}
+
+func TestGeneratorStateStoreLookahead(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	store := &memStore{}
+	g, _ := ulid.NewGenerator(ulid.WithClock(clock.Now), ulid.WithStateStore(store, time.Second))
+
+	// Generate past the last save, which happened on the first ULID.
+	var last ulid.ULID
+	for _, d := range []time.Duration{0, 0, 0, 300 * time.Millisecond, 0, 600 * time.Millisecond} {
+		clock.Add(d)
+		last = g.MustNew()
+	}
+	if got, want := store.saves, 1; got != want {
+		t.Fatalf("got %d saves, want %d", got, want)
+	}
+
+	// Crash and restart within the same millisecond.
+	g, _ = ulid.NewGenerator(ulid.WithClock(clock.Now), ulid.WithStateStore(store, time.Second))
+	next := g.MustNew()
+	if next.Compare(last) <= 0 {
+		t.Fatalf("%s <= %s after restart", next, last)
+	}
+
+	// The restarted Generator continues right after the interval of the last
+	// save, not further into the future.
+	if got, want := next.Time(), ulid.Timestamp(time.Unix(1e6+1, 0))+1; got != want {
+		t.Errorf("got time %d after restart, want %d", got, want)
+	}
+
+	// Once the clock caught up, it's used again.
+	clock.Add(time.Second)
+	if got, want := g.MustNew().Time(), ulid.Timestamp(clock.Now()); got != want {
+		t.Errorf("got time %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import "sync/atomic"
+
+// Stats holds counters about the ULIDs generated by a MonotonicEntropy or a
+// Generator, for export to metrics systems. All counters are cumulative since
+// creation, except MaxBurst which is a high-water mark.
+type Stats struct {
+	// Generated is the number of ULIDs successfully generated.
+	Generated uint64
+
+	// Increments is the number of ULIDs generated within the same timestamp as
+	// the previous one, by incrementing its entropy.
+	Increments uint64
+
+	// Overflows is the number of times incrementing the previous ULID's
+	// entropy failed with ErrMonotonicOverflow.
+	Overflows uint64
+
+	// ClockRegressions is the number of times the timestamp was lower than
+	// the previous one.
+	ClockRegressions uint64
+
+	// MaxBurst is the largest number of ULIDs generated within a single
+	// timestamp.
+	MaxBurst uint64
+}
+
+// counters tracks Stats. Its fields are accessed atomically, so that Stats
+// can be read concurrently with generation, except for burst, which is
+// guarded by its owner. It must be the first field of its owner to guarantee
+// 64-bit alignment on 32-bit platforms.
+type counters struct {
+	generated   uint64
+	increments  uint64
+	overflows   uint64
+	regressions uint64
+	maxBurst    uint64
+	burst       uint64
+}
+
+// record updates the counters after an attempt to generate a ULID, where same
+// reports whether its timestamp was the same as the previous one's, and
+// regressed whether it was lower.
+func (c *counters) record(same, regressed bool, err error) {
+	if regressed {
+		atomic.AddUint64(&c.regressions, 1)
+	}
+
+	if err == ErrMonotonicOverflow {
+		atomic.AddUint64(&c.overflows, 1)
+	}
+
+	if err != nil {
+		return
+	}
+
+	atomic.AddUint64(&c.generated, 1)
+	if same {
+		atomic.AddUint64(&c.increments, 1)
+		c.burst++
+	} else {
+		c.burst = 1
+	}
+
+	if c.burst > atomic.LoadUint64(&c.maxBurst) {
+		atomic.StoreUint64(&c.maxBurst, c.burst)
+	}
+}
+
+// reserve updates the counters after n more ULIDs were generated at once,
+// within the same timestamp as the previous one.
+func (c *counters) reserve(n uint64) {
+	atomic.AddUint64(&c.generated, n)
+	atomic.AddUint64(&c.increments, n)
+	c.burst += n
+
+	if c.burst > atomic.LoadUint64(&c.maxBurst) {
+		atomic.StoreUint64(&c.maxBurst, c.burst)
+	}
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Generated:        atomic.LoadUint64(&c.generated),
+		Increments:       atomic.LoadUint64(&c.increments),
+		Overflows:        atomic.LoadUint64(&c.overflows),
+		ClockRegressions: atomic.LoadUint64(&c.regressions),
+		MaxBurst:         atomic.LoadUint64(&c.maxBurst),
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestMonotonicStats(t *testing.T) {
+	t.Parallel()
+
+	entropy := ulid.Monotonic(io.MultiReader(
+		bytes.NewReader(bytes.Repeat([]byte{0x01}, 30)), // Entropy for the first three timestamps
+		bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)), // Entropy bound to overflow
+	), 1)
+
+	for _, ms := range []uint64{10, 10, 10, 11, 12} {
+		_ = ulid.MustNew(ms, entropy)
+	}
+	_ = ulid.MustNew(5, entropy) // Clock regression.
+	if _, err := ulid.New(5, entropy); err != ulid.ErrMonotonicOverflow {
+		t.Fatalf("got err %v, want %v", err, ulid.ErrMonotonicOverflow)
+	}
+
+	want := ulid.Stats{
+		Generated:        6,
+		Increments:       2,
+		Overflows:        1,
+		ClockRegressions: 1,
+		MaxBurst:         3,
+	}
+	if got := entropy.Stats(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+}
+
+func TestGeneratorStats(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Unix(1e6, 0)}
+	g, err := ulid.NewGenerator(ulid.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []time.Duration{0, 0, 0, 0, time.Millisecond, -time.Second, 2 * time.Second} {
+		clock.Add(d)
+		_ = g.MustNew()
+	}
+
+	want := ulid.Stats{
+		Generated:        7,
+		Increments:       4,
+		Overflows:        0,
+		ClockRegressions: 1,
+		MaxBurst:         4,
+	}
+	if got := g.Stats(); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	t.Parallel()
+
+	g, err := ulid.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_ = g.MustNew()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = g.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := g.Stats().Generated, uint64(8000); got != want {
+		t.Errorf("got %d generated, want %d", got, want)
+	}
+}
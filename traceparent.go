@@ -0,0 +1,152 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// TraceIDSize is the length of a W3C Trace Context trace ID, which is a ULID
+// encoded as lowercase hex.
+const TraceIDSize = 32
+
+// TraceID returns the ULID as a W3C Trace Context trace ID: 32 lowercase hex
+// characters. Since ULIDs are time-sortable, so are the resulting trace IDs.
+func (id ULID) TraceID() string {
+	return hex.EncodeToString(id[:])
+}
+
+// ParseTraceID parses a W3C Trace Context trace ID into a ULID. As required by
+// the specification, it rejects uppercase hex with ErrInvalidCharacters, and
+// the all-zero trace ID with ErrZeroValue.
+func ParseTraceID(s string) (id ULID, err error) {
+	if len(s) != TraceIDSize {
+		return id, ErrDataSize
+	}
+
+	if err := decodeHex(id[:], s, 0, s); err != nil {
+		return id, err
+	}
+
+	if id.IsZero() {
+		return id, ErrZeroValue
+	}
+
+	return id, nil
+}
+
+// TraceParent is a W3C Trace Context traceparent header carrying a ULID as its
+// trace ID.
+type TraceParent struct {
+	// Version is the format version, which must not be 0xff.
+	Version byte
+
+	// TraceID identifies the whole trace.
+	TraceID ULID
+
+	// ParentID identifies the caller's span.
+	ParentID [8]byte
+
+	// Flags are the trace flags, such as FlagSampled.
+	Flags byte
+}
+
+// FlagSampled is the trace flag set when the caller may have recorded trace
+// data.
+const FlagSampled byte = 0x01
+
+// traceParentSize is the length of a version 00 traceparent header.
+const traceParentSize = 55
+
+// ParseTraceParent parses a traceparent header. Headers of versions after 00
+// may carry additional dash-separated fields, which are ignored. It returns
+// ErrZeroValue for the all-zero trace ID, ErrTraceParent for malformed headers
+// and the all-zero parent ID, and a *ParseError for invalid hex characters.
+func ParseTraceParent(s string) (tp TraceParent, err error) {
+	if len(s) < traceParentSize || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tp, ErrTraceParent
+	}
+
+	var version [1]byte
+	if err := decodeHex(version[:], s[:2], 0, s); err != nil {
+		return tp, err
+	}
+
+	tp.Version = version[0]
+	switch {
+	case tp.Version == 0xff:
+		return tp, ErrTraceParent
+	case tp.Version == 0 && len(s) != traceParentSize:
+		return tp, ErrTraceParent
+	case len(s) > traceParentSize && s[traceParentSize] != '-':
+		return tp, ErrTraceParent
+	}
+
+	if err := decodeHex(tp.TraceID[:], s[3:35], 3, s); err != nil {
+		return tp, err
+	}
+
+	if err := decodeHex(tp.ParentID[:], s[36:52], 36, s); err != nil {
+		return tp, err
+	}
+
+	var flags [1]byte
+	if err := decodeHex(flags[:], s[53:55], 53, s); err != nil {
+		return tp, err
+	}
+	tp.Flags = flags[0]
+
+	if tp.TraceID.IsZero() {
+		return tp, ErrZeroValue
+	}
+
+	if tp.ParentID == [8]byte{} {
+		return tp, ErrTraceParent
+	}
+
+	return tp, nil
+}
+
+// String returns the traceparent header value.
+func (tp TraceParent) String() string {
+	var b strings.Builder
+	b.Grow(traceParentSize)
+	b.WriteString(hex.EncodeToString([]byte{tp.Version}))
+	b.WriteByte('-')
+	b.WriteString(tp.TraceID.TraceID())
+	b.WriteByte('-')
+	b.WriteString(hex.EncodeToString(tp.ParentID[:]))
+	b.WriteByte('-')
+	b.WriteString(hex.EncodeToString([]byte{tp.Flags}))
+	return b.String()
+}
+
+// Sampled reports whether FlagSampled is set.
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&FlagSampled != 0
+}
+
+// decodeHex decodes the lowercase hex string s, found at offset in input, into
+// dst, which must be half as long as s.
+func decodeHex(dst []byte, s string, offset int, input string) error {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return &ParseError{Input: input, Offset: offset + i, Err: ErrInvalidCharacters}
+		}
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestTraceID(t *testing.T) {
+	t.Parallel()
+
+	id := ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	traceID := id.TraceID()
+	if got, want := traceID, "01563e3ab5d3d6764c61efb99302bd5b"; got != want {
+		t.Errorf("TraceID: got %q, want %q", got, want)
+	}
+
+	got, err := ulid.ParseTraceID(traceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Errorf("ParseTraceID: got %v, want %v", got, id)
+	}
+
+	for _, tc := range []struct {
+		in  string
+		err error
+	}{
+		{"", ulid.ErrDataSize},
+		{traceID[:31], ulid.ErrDataSize},
+		{strings.ToUpper(traceID), ulid.ErrInvalidCharacters},
+		{traceID[:31] + "g", ulid.ErrInvalidCharacters},
+		{strings.Repeat("0", 32), ulid.ErrZeroValue},
+	} {
+		if _, err := ulid.ParseTraceID(tc.in); !errors.Is(err, tc.err) {
+			t.Errorf("ParseTraceID(%q): got err %v, want %v", tc.in, err, tc.err)
+		}
+	}
+}
+
+func TestTraceParent(t *testing.T) {
+	t.Parallel()
+
+	const header = "00-01563e3ab5d3d6764c61efb99302bd5b-00f067aa0ba902b7-01"
+
+	tp, err := ulid.ParseTraceParent(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ulid.TraceParent{
+		TraceID:  ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV"),
+		ParentID: [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		Flags:    ulid.FlagSampled,
+	}
+	if tp != want {
+		t.Errorf("ParseTraceParent: got %+v, want %+v", tp, want)
+	}
+
+	if !tp.Sampled() {
+		t.Error("Sampled: got false, want true")
+	}
+
+	if got := tp.String(); got != header {
+		t.Errorf("String: got %q, want %q", got, header)
+	}
+
+	future := "cc" + header[2:] + "-what-the-future-holds"
+	if tp, err := ulid.ParseTraceParent(future); err != nil || tp.Version != 0xcc {
+		t.Errorf("ParseTraceParent(%q): got %+v, %v", future, tp, err)
+	}
+
+	for _, tc := range []struct {
+		in  string
+		err error
+	}{
+		{"", ulid.ErrTraceParent},
+		{header[:54], ulid.ErrTraceParent},
+		{header + "-", ulid.ErrTraceParent},
+		{"cc" + header[2:] + "x", ulid.ErrTraceParent},
+		{strings.Replace(header, "-", "_", 1), ulid.ErrTraceParent},
+		{"ff" + header[2:], ulid.ErrTraceParent},
+		{strings.ToUpper(header), ulid.ErrInvalidCharacters},
+		{header[:54] + "x", ulid.ErrInvalidCharacters},
+		{"00-" + strings.Repeat("0", 32) + header[35:], ulid.ErrZeroValue},
+		{header[:36] + strings.Repeat("0", 16) + header[52:], ulid.ErrTraceParent},
+	} {
+		if _, err := ulid.ParseTraceParent(tc.in); !errors.Is(err, tc.err) {
+			t.Errorf("ParseTraceParent(%q): got err %v, want %v", tc.in, err, tc.err)
+		}
+	}
+
+	var perr *ulid.ParseError
+	if _, err := ulid.ParseTraceParent(header[:54] + "x"); !errors.As(err, &perr) || perr.Offset != 54 {
+		t.Errorf("ParseTraceParent: got err %v, want *ParseError at offset 54", err)
+	}
+}
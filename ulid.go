@@ -19,10 +19,10 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"math/bits"
-	"math/rand"
 	"sync"
 	"time"
 )
@@ -64,6 +64,10 @@ var (
 	// than MaxTime.
 	ErrBigTime = errors.New("ulid: time too big")
 
+	// ErrSmallTime is returned when constructing a ULID with a time that is
+	// before the epoch of its Codec.
+	ErrSmallTime = errors.New("ulid: time too small")
+
 	// ErrOverflow is returned when unmarshaling a ULID whose first character is
 	// larger than 7, thereby exceeding the valid bit depth of 128.
 	ErrOverflow = errors.New("ulid: overflow when unmarshaling")
@@ -76,6 +80,33 @@ var (
 	// into the ULID.
 	ErrScanValue = errors.New("ulid: source value must be a string or byte slice")
 
+	// ErrSignature is returned when verifying a signed ULID whose signature
+	// doesn't match any of the given keys.
+	ErrSignature = errors.New("ulid: invalid signature")
+
+	// ErrInvalidState is returned when unmarshaling generator state that is
+	// malformed or of an unsupported version.
+	ErrInvalidState = errors.New("ulid: invalid state")
+
+	// ErrZeroValue is returned by a Validator for zero-value ULIDs.
+	ErrZeroValue = errors.New("ulid: zero value")
+
+	// ErrTimeBeforeMin is returned by a Validator for ULIDs whose time is
+	// before its minimum.
+	ErrTimeBeforeMin = errors.New("ulid: time before minimum")
+
+	// ErrTimeInFuture is returned by a Validator for ULIDs whose time is in
+	// the future, beyond the tolerated clock skew.
+	ErrTimeInFuture = errors.New("ulid: time in the future")
+
+	// ErrTraceParent is returned when parsing a malformed W3C traceparent
+	// header, or one with an invalid version or parent ID.
+	ErrTraceParent = errors.New("ulid: invalid traceparent")
+
+	// ErrUUIDVersion is returned when converting a UUID whose version has no
+	// timestamp to convert from.
+	ErrUUIDVersion = errors.New("ulid: unsupported UUID version")
+
 	// Zero is a zero-value ULID.
 	Zero ULID
 )
@@ -133,12 +164,12 @@ func MustNewDefault(t time.Time) ULID {
 }
 
 var defaultEntropy = func() io.Reader {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return &LockedMonotonicReader{MonotonicReader: Monotonic(rng, 0)}
+	return &LockedMonotonicReader{MonotonicReader: Monotonic(Reseeding(nil, 0), 0)}
 }()
 
 // DefaultEntropy returns a thread-safe per process monotonically increasing
-// entropy source.
+// entropy source. It is a Monotonic source over a Reseeding source that is
+// seeded from crypto/rand.
 func DefaultEntropy() io.Reader {
 	return defaultEntropy
 }
@@ -155,8 +186,9 @@ func Make() (id ULID) {
 // Parse parses an encoded ULID, returning an error in case of failure.
 //
 // ErrDataSize is returned if the len(ulid) is different from an encoded
-// ULID's length. Invalid encodings produce undefined ULIDs. For a version that
-// returns an error instead, see ParseStrict.
+// ULID's length. A *ParseError wrapping ErrOverflow is returned if the first
+// character exceeds the valid bit depth. Invalid encodings produce undefined
+// ULIDs. For a version that returns an error instead, see ParseStrict.
 func Parse(ulid string) (id ULID, err error) {
 	return id, parse([]byte(ulid), false, &id)
 }
@@ -167,11 +199,36 @@ func Parse(ulid string) (id ULID, err error) {
 // only of valid base32 characters. It is slightly slower than Parse.
 //
 // ErrDataSize is returned if the len(ulid) is different from an encoded
-// ULID's length. Invalid encodings return ErrInvalidCharacters.
+// ULID's length. Invalid encodings return a *ParseError wrapping
+// ErrInvalidCharacters, which points at the first invalid character.
 func ParseStrict(ulid string) (id ULID, err error) {
 	return id, parse([]byte(ulid), true, &id)
 }
 
+// ParseError describes a failure to parse an encoded ULID, pointing at the
+// offending character. It wraps the reason for the failure, so that e.g.
+// errors.Is(err, ErrInvalidCharacters) holds.
+type ParseError struct {
+	// Input is the encoded ULID that failed to parse.
+	Input string
+
+	// Offset is the byte offset of the offending character in Input.
+	Offset int
+
+	// Err is the reason for the failure, ErrInvalidCharacters or ErrOverflow.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v: %q at offset %d", e.Err, e.Input[e.Offset:e.Offset+1], e.Offset)
+}
+
+// Unwrap returns the reason for the failure.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 func parse(v []byte, strict bool, id *ULID) error {
 	// Check if a base32 encoded ULID is the right length.
 	if len(v) != EncodedSize {
@@ -207,7 +264,11 @@ func parse(v []byte, strict bool, id *ULID) error {
 			dec[v[23]] == 0xFF ||
 			dec[v[24]] == 0xFF ||
 			dec[v[25]] == 0xFF) {
-		return ErrInvalidCharacters
+		offset := 0
+		for dec[v[offset]] != 0xFF {
+			offset++
+		}
+		return &ParseError{Input: string(v), Offset: offset, Err: ErrInvalidCharacters}
 	}
 
 	// Check if the first character in a base32 encoded ULID will overflow. This
@@ -216,7 +277,7 @@ func parse(v []byte, strict bool, id *ULID) error {
 	//
 	// See https://github.com/oklog/ulid/issues/9 for details.
 	if v[0] > '7' {
-		return ErrOverflow
+		return &ParseError{Input: string(v), Offset: 0, Err: ErrOverflow}
 	}
 
 	// Use an optimized unrolled loop (from https://github.com/RobThree/NUlid)
@@ -432,6 +493,26 @@ var maxTime = ULID{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}.Time()
 // can be encoded in a ULID.
 func MaxTime() uint64 { return maxTime }
 
+// MinAt returns the smallest ULID with the given Unix milliseconds timestamp,
+// i.e. one with all entropy bits cleared. Timestamps larger than MaxTime are
+// clamped to MaxTime.
+func MinAt(ms uint64) (id ULID) {
+	if ms > maxTime {
+		ms = maxTime
+	}
+	_ = id.SetTime(ms)
+	return id
+}
+
+// MaxAt returns the largest ULID with the given Unix milliseconds timestamp,
+// i.e. one with all entropy bits set. Timestamps larger than MaxTime are
+// clamped to MaxTime.
+func MaxAt(ms uint64) ULID {
+	id := MinAt(ms)
+	copy(id[6:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	return id
+}
+
 // Now is a convenience function that returns the current
 // UTC time in Unix milliseconds. Equivalent to:
 //
@@ -575,13 +656,33 @@ func (id ULID) Value() (driver.Value, error) {
 //
 // The returned type isn't safe for concurrent use.
 func Monotonic(entropy io.Reader, inc uint64) *MonotonicEntropy {
+	return MonotonicRange(entropy, 1, inc)
+}
+
+// MonotonicRange is like Monotonic, but increments entropy by a uniformly
+// random number between min and max inclusive. Narrow ranges make the capacity
+// of a millisecond predictable, e.g. for tuning and testing bursts; see
+// MonotonicEntropy.Remaining.
+//
+// Passing `min == 0` results in 1, and `max == 0` in `math.MaxUint32`. It
+// panics if max < min.
+func MonotonicRange(entropy io.Reader, min, max uint64) *MonotonicEntropy {
 	m := MonotonicEntropy{
 		Reader: bufio.NewReader(entropy),
-		inc:    inc,
+		min:    min,
+		max:    max,
+	}
+
+	if m.min == 0 {
+		m.min = 1
 	}
 
-	if m.inc == 0 {
-		m.inc = math.MaxUint32
+	if m.max == 0 {
+		m.max = math.MaxUint32
+	}
+
+	if m.max < m.min {
+		panic("ulid: monotonic increment range with max < min")
 	}
 
 	if rng, ok := entropy.(rng); ok {
@@ -610,9 +711,11 @@ func (r *LockedMonotonicReader) MonotonicRead(ms uint64, p []byte) (err error) {
 
 // MonotonicEntropy is an opaque type that provides monotonic entropy.
 type MonotonicEntropy struct {
+	stats counters
 	io.Reader
 	ms      uint64
-	inc     uint64
+	min     uint64
+	max     uint64
 	entropy uint80
 	rand    [8]byte
 	rng     rng
@@ -620,18 +723,57 @@ type MonotonicEntropy struct {
 
 // MonotonicRead implements the MonotonicReader interface.
 func (m *MonotonicEntropy) MonotonicRead(ms uint64, entropy []byte) (err error) {
-	if !m.entropy.IsZero() && m.ms == ms {
+	same, regressed := !m.entropy.IsZero() && m.ms == ms, ms < m.ms
+	if same {
 		err = m.increment()
 		m.entropy.AppendTo(entropy)
 	} else if _, err = io.ReadFull(m.Reader, entropy); err == nil {
 		m.ms = ms
 		m.entropy.SetBytes(entropy)
 	}
+	m.stats.record(same, regressed, err)
 	return err
 }
 
+// Stats returns the counters of m. It's safe to call concurrently with
+// MonotonicRead.
+func (m *MonotonicEntropy) Stats() Stats {
+	return m.stats.stats()
+}
+
+// Remaining returns how many more increments fit into the entropy of the
+// millisecond of the last MonotonicRead before ErrMonotonicOverflow: at least
+// worst, if every increment is the largest possible, and expected on average.
+// Both saturate at math.MaxUint64.
+func (m *MonotonicEntropy) Remaining() (worst, expected uint64) {
+	// The space left is the complement of the entropy, as an 80 bit number.
+	hi, lo := uint64(^m.entropy.Hi), ^m.entropy.Lo
+
+	worst = div80(hi, lo, m.max)
+
+	// The mean increment is (m.min+m.max)/2, so divide twice the space by
+	// their sum, unless it overflows.
+	if sum := m.min + m.max; sum >= m.min {
+		expected = div80(hi<<1|lo>>63, lo<<1, sum)
+	} else {
+		expected = div80(hi, lo, m.min/2+m.max/2)
+	}
+
+	return worst, expected
+}
+
+// div80 returns the 128 bit number hi:lo divided by d, saturating at
+// math.MaxUint64.
+func div80(hi, lo, d uint64) uint64 {
+	if hi >= d {
+		return math.MaxUint64
+	}
+	q, _ := bits.Div64(hi, lo, d)
+	return q
+}
+
 // increment the previous entropy number with a random number
-// of up to m.inc (inclusive).
+// between m.min and m.max (inclusive).
 func (m *MonotonicEntropy) increment() error {
 	if inc, err := m.random(); err != nil {
 		return err
@@ -641,40 +783,43 @@ func (m *MonotonicEntropy) increment() error {
 	return nil
 }
 
-// random returns a uniform random value in [1, m.inc), reading entropy
-// from m.Reader. When m.inc == 0 || m.inc == 1, it returns 1.
+// random returns a uniform random value in [m.min, m.max], reading entropy
+// from m.Reader. When m.min == m.max, it returns m.min.
 // Adapted from: https://golang.org/pkg/crypto/rand/#Int
 func (m *MonotonicEntropy) random() (inc uint64, err error) {
-	if m.inc <= 1 {
-		return 1, nil
+	// span is the largest value to add to m.min.
+	span := m.max - m.min
+	if span == 0 {
+		return m.min, nil
 	}
 
 	// Fast path for using a underlying rand.Rand directly.
-	if m.rng != nil {
-		// Range: [1, m.inc)
-		return 1 + uint64(m.rng.Int63n(int64(m.inc))), nil
+	if m.rng != nil && span < math.MaxInt64 {
+		// Range: [m.min, m.max]
+		return m.min + uint64(m.rng.Int63n(int64(span)+1)), nil
 	}
 
-	// bitLen is the maximum bit length needed to encode a value < m.inc.
-	bitLen := bits.Len64(m.inc)
+	// bitLen is the maximum bit length needed to encode a value <= span.
+	bitLen := bits.Len64(span)
 
-	// byteLen is the maximum byte length needed to encode a value < m.inc.
+	// byteLen is the maximum byte length needed to encode a value <= span.
 	byteLen := uint(bitLen+7) / 8
 
-	// msbitLen is the number of bits in the most significant byte of m.inc-1.
+	// msbitLen is the number of bits in the most significant byte of span.
 	msbitLen := uint(bitLen % 8)
 	if msbitLen == 0 {
 		msbitLen = 8
 	}
 
-	for inc == 0 || inc >= m.inc {
+	for {
+		m.rand = [8]byte{}
 		if _, err = io.ReadFull(m.Reader, m.rand[:byteLen]); err != nil {
 			return 0, err
 		}
 
-		// Clear bits in the first byte to increase the probability
-		// that the candidate is < m.inc.
-		m.rand[0] &= uint8(int(1<<msbitLen) - 1)
+		// Clear bits in the most significant byte to increase the
+		// probability that the candidate is <= span.
+		m.rand[byteLen-1] &= uint8(int(1<<msbitLen) - 1)
 
 		// Convert the read bytes into an uint64 with byteLen
 		// Optimized unrolled loop.
@@ -688,10 +833,14 @@ func (m *MonotonicEntropy) random() (inc uint64, err error) {
 		case 5, 6, 7, 8:
 			inc = uint64(binary.LittleEndian.Uint64(m.rand[:8]))
 		}
+
+		if inc <= span {
+			break
+		}
 	}
 
-	// Range: [1, m.inc)
-	return 1 + inc, nil
+	// Range: [m.min, m.max]
+	return m.min + inc, nil
 }
 
 type uint80 struct {
@@ -720,3 +869,30 @@ func (u *uint80) Add(n uint64) (overflow bool) {
 func (u uint80) IsZero() bool {
 	return u.Hi == 0 && u.Lo == 0
 }
+
+func (u uint80) bitLen() uint {
+	if u.Hi != 0 {
+		return 64 + uint(bits.Len16(u.Hi))
+	}
+	return uint(bits.Len64(u.Lo))
+}
+
+// Top returns the n most significant bits of u, for n <= 64.
+func (u uint80) Top(n uint) uint64 {
+	if n <= 16 {
+		return uint64(u.Hi >> (16 - n))
+	}
+	return uint64(u.Hi)<<(n-16) | u.Lo>>(80-n)
+}
+
+// SetTop sets the n most significant bits of u to v, for n <= 64.
+func (u *uint80) SetTop(n uint, v uint64) {
+	if n <= 16 {
+		shift := 16 - n
+		u.Hi = u.Hi&(1<<shift-1) | uint16(v<<shift)
+		return
+	}
+	u.Hi = uint16(v >> (n - 16))
+	shift := 80 - n
+	u.Lo = u.Lo&(1<<shift-1) | v<<shift
+}
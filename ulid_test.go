@@ -16,6 +16,8 @@ package ulid_test
 import (
 	"bytes"
 	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -229,13 +231,53 @@ func TestParseStrictInvalidCharacters(t *testing.T) {
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := ulid.ParseStrict(tt.input)
-			if err != ulid.ErrInvalidCharacters {
+			if !errors.Is(err, ulid.ErrInvalidCharacters) {
 				t.Errorf("Parse(%q): got err %v, want %v", tt.input, err, ulid.ErrInvalidCharacters)
 			}
 		})
 	}
 }
 
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input  string
+		parse  func(string) (ulid.ULID, error)
+		offset int
+		err    error
+	}{
+		{"0000XSNJG0MQJHBF4QX1EFD6YU", ulid.ParseStrict, 25, ulid.ErrInvalidCharacters},
+		{"0000XSNJG0MQ-HBF4QX1EFD6Y3", ulid.ParseStrict, 12, ulid.ErrInvalidCharacters},
+		{"0000XSNJG0MQ-HBF4QX1EFD6YU", ulid.ParseStrict, 12, ulid.ErrInvalidCharacters},
+		{"8000XSNJG0MQJHBF4QX1EFD6Y3", ulid.ParseStrict, 0, ulid.ErrOverflow},
+		{"8000XSNJG0MQJHBF4QX1EFD6Y3", ulid.Parse, 0, ulid.ErrOverflow},
+	} {
+		_, err := tc.parse(tc.input)
+
+		var perr *ulid.ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("%s: got err %v, want a *ParseError", tc.input, err)
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: got err %v, want %v", tc.input, err, tc.err)
+		}
+		if perr.Input != tc.input || perr.Offset != tc.offset {
+			t.Errorf("%s: got input %q at offset %d, want offset %d", tc.input, perr.Input, perr.Offset, tc.offset)
+		}
+	}
+
+	_, err := ulid.ParseStrict("0000XSNJG0MQ-HBF4QX1EFD6Y3")
+	if got, want := err.Error(), `ulid: bad data characters when unmarshaling: "-" at offset 12`; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+
+	// Length errors don't point at any character.
+	if _, err := ulid.ParseStrict(""); err != ulid.ErrDataSize {
+		t.Errorf("got err %v, want %v", err, ulid.ErrDataSize)
+	}
+}
+
 func TestAlizainCompatibility(t *testing.T) {
 	t.Parallel()
 
@@ -357,6 +399,26 @@ func TestParseRobustness(t *testing.T) {
 	}
 }
 
+func TestMinMaxAt(t *testing.T) {
+	t.Parallel()
+
+	prop := func(ms uint64, id ulid.ULID) bool {
+		ms %= ulid.MaxTime() + 1
+		_ = id.SetTime(ms)
+		lo, hi := ulid.MinAt(ms), ulid.MaxAt(ms)
+		return lo.Time() == ms && hi.Time() == ms &&
+			lo.Compare(id) <= 0 && hi.Compare(id) >= 0
+	}
+
+	if err := quick.Check(prop, &quick.Config{MaxCount: 1e4}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := ulid.MaxAt(math.MaxUint64), ulid.MustParse("7ZZZZZZZZZZZZZZZZZZZZZZZZZ"); got != want {
+		t.Errorf("MaxAt(MaxUint64): got %s, want %s", got, want)
+	}
+}
+
 func TestNow(t *testing.T) {
 	t.Parallel()
 
@@ -549,7 +611,7 @@ func TestOverflowHandling(t *testing.T) {
 		"80000000000000000000000001": ulid.ErrOverflow,
 		"ZZZZZZZZZZZZZZZZZZZZZZZZZZ": ulid.ErrOverflow,
 	} {
-		if _, have := ulid.Parse(s); want != have {
+		if _, have := ulid.Parse(s); !errors.Is(have, want) {
 			t.Errorf("%s: want error %v, have %v", s, want, have)
 		}
 	}
@@ -629,6 +691,95 @@ func TestMonotonic(t *testing.T) {
 	}
 }
 
+func TestMonotonicRange(t *testing.T) {
+	t.Parallel()
+
+	for _, e := range []struct {
+		name string
+		mk   func() io.Reader
+	}{
+		{"cryptorand", func() io.Reader { return crand.Reader }},
+		{"mathrand", func() io.Reader { return rand.New(rand.NewSource(1)) }},
+	} {
+		for _, r := range []struct{ min, max uint64 }{
+			{5, 5},
+			{5, 7},
+			{250, 260},
+			{1 << 20, 1<<20 + 3},
+		} {
+			entropy := ulid.MonotonicRange(e.mk(), r.min, r.max)
+			prev := ulid.MustNew(123, entropy)
+			seen := map[uint64]bool{}
+			for i := 0; i < 1000; i++ {
+				next := ulid.MustNew(123, entropy)
+				inc := binary.BigEndian.Uint64(next[8:]) - binary.BigEndian.Uint64(prev[8:])
+				if inc < r.min || inc > r.max {
+					t.Fatalf("%s: range [%d, %d]: got increment %d", e.name, r.min, r.max, inc)
+				}
+				seen[inc] = true
+				prev = next
+			}
+
+			if got, want := uint64(len(seen)), r.max-r.min+1; got != want {
+				t.Errorf("%s: range [%d, %d]: got %d distinct increments, want %d", e.name, r.min, r.max, got, want)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MonotonicRange with max < min: got no panic")
+		}
+	}()
+	ulid.MonotonicRange(crand.Reader, 2, 1)
+}
+
+func TestMonotonicRemaining(t *testing.T) {
+	t.Parallel()
+
+	// Entropy 100 increments away from overflow.
+	first := append(bytes.Repeat([]byte{0xFF}, 9), 0xFF-100)
+
+	for _, tc := range []struct {
+		min, max        uint64
+		worst, expected uint64
+	}{
+		{10, 10, 10, 10},
+		{1, 3, 33, 50},
+		{1, 1, 100, 100},
+		{7, 7, 14, 14},
+	} {
+		entropy := ulid.MonotonicRange(io.MultiReader(bytes.NewReader(first), crand.Reader), tc.min, tc.max)
+		_ = ulid.MustNew(123, entropy)
+
+		worst, expected := entropy.Remaining()
+		if worst != tc.worst || expected != tc.expected {
+			t.Errorf("range [%d, %d]: got remaining (%d, %d), want (%d, %d)",
+				tc.min, tc.max, worst, expected, tc.worst, tc.expected)
+		}
+
+		// At least the worst case number of increments must succeed.
+		for i := uint64(0); i < worst; i++ {
+			if _, err := ulid.New(123, entropy); err != nil {
+				t.Fatalf("range [%d, %d]: increment %d: %v", tc.min, tc.max, i, err)
+			}
+		}
+	}
+
+	// The remaining increments of fresh entropy saturate.
+	worst, expected := ulid.MonotonicRange(crand.Reader, 1, 1).Remaining()
+	if worst != math.MaxUint64 || expected != math.MaxUint64 {
+		t.Errorf("fresh: got remaining (%d, %d), want saturated", worst, expected)
+	}
+
+	// The default increments of up to math.MaxUint32 leave about 2^48 in the
+	// worst case, and 2^49 on average.
+	worst, expected = ulid.Monotonic(crand.Reader, 0).Remaining()
+	if worst != 1<<48+1<<16 || expected != 1<<49-1 {
+		t.Errorf("default: got remaining (%d, %d)", worst, expected)
+	}
+}
+
 func TestMonotonicOverflow(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,169 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ulidhttp provides net/http middleware that assigns a ULID request ID
+// to every request, propagating trusted incoming IDs.
+package ulidhttp
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// DefaultHeader is the header that carries request IDs, unless configured
+// otherwise with WithHeader.
+const DefaultHeader = "X-Request-ID"
+
+// A TrustPolicy reports whether the request ID header of an incoming request
+// should be trusted. Untrusted requests get a new request ID.
+type TrustPolicy func(r *http.Request) bool
+
+// TrustAll trusts the request IDs of all requests, letting any client choose
+// its own. Only use it behind proxies that strip or set the header.
+func TrustAll(*http.Request) bool { return true }
+
+// TrustNone trusts no request IDs, always generating new ones. It's the
+// default; callers behind trusted proxies opt in to propagating their request
+// IDs with WithTrust and TrustNetworks.
+func TrustNone(*http.Request) bool { return false }
+
+// TrustNetworks returns a TrustPolicy that trusts requests whose remote
+// address is within one of the given networks, e.g. those of internal proxies.
+func TrustNetworks(networks ...*net.IPNet) TrustPolicy {
+	return func(r *http.Request) bool {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		ip := net.ParseIP(host)
+		for _, n := range networks {
+			if ip != nil && n.Contains(ip) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// An Option configures the middleware returned by Middleware.
+type Option func(*middleware)
+
+// WithHeader configures the header that carries request IDs.
+func WithHeader(name string) Option {
+	return func(m *middleware) { m.header = http.CanonicalHeaderKey(name) }
+}
+
+// WithTrust configures the policy for trusting incoming request IDs, which is
+// TrustNone by default.
+func WithTrust(policy TrustPolicy) Option {
+	return func(m *middleware) { m.trust = policy }
+}
+
+// WithValidator configures the validator for incoming request IDs. Invalid
+// ones are replaced by new request IDs. The default validator parses strictly
+// and rejects zero-value ULIDs.
+func WithValidator(v ulid.Validator) Option {
+	return func(m *middleware) { m.validator = v }
+}
+
+// WithEcho configures whether the request ID is set as a header on the
+// response, which is the default.
+func WithEcho(echo bool) Option {
+	return func(m *middleware) { m.echo = echo }
+}
+
+// WithGenerator configures the Generator of new request IDs. By default, the
+// Generator carried by the request's context is used, if any; see
+// ulid.NewFromContext.
+func WithGenerator(g *ulid.Generator) Option {
+	return func(m *middleware) { m.generator = g }
+}
+
+// Middleware returns net/http middleware that makes sure every request has a
+// request ID. Incoming request IDs are kept if they're trusted and valid;
+// otherwise, new ones are generated. None are trusted unless configured with
+// WithTrust. The request ID is set on the request's header and context, where
+// handlers can find it with FromContext, and optionally on the response's
+// header.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	m := middleware{
+		header:    DefaultHeader,
+		trust:     TrustNone,
+		validator: ulid.Validator{Strict: true, NotZero: true},
+		echo:      true,
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := m.requestID(r)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			// WithContext makes a shallow copy, so clone the header too to
+			// leave the caller's request untouched.
+			r = r.WithContext(NewContext(r.Context(), id))
+			r.Header = r.Header.Clone()
+			r.Header.Set(m.header, id.String())
+			if m.echo {
+				w.Header().Set(m.header, id.String())
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type middleware struct {
+	header    string
+	trust     TrustPolicy
+	validator ulid.Validator
+	echo      bool
+	generator *ulid.Generator
+}
+
+func (m *middleware) requestID(r *http.Request) (ulid.ULID, error) {
+	if v := r.Header.Get(m.header); v != "" && m.trust(r) {
+		if id, err := ulid.ParseWith(v, m.validator); err == nil {
+			return id, nil
+		}
+	}
+
+	if m.generator != nil {
+		return m.generator.New()
+	}
+
+	return ulid.NewFromContext(r.Context())
+}
+
+// RequestIDKey is the ulid.ContextKey of request IDs.
+var RequestIDKey = ulid.NewContextKey("request ID")
+
+// NewContext returns a copy of ctx carrying the given request ID.
+func NewContext(ctx context.Context, id ulid.ULID) context.Context {
+	return ulid.NewContext(ctx, RequestIDKey, id)
+}
+
+// FromContext returns the request ID carried by ctx, if any.
+func FromContext(ctx context.Context) (id ulid.ULID, ok bool) {
+	return ulid.FromContext(ctx, RequestIDKey)
+}
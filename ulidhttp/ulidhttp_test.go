@@ -0,0 +1,206 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulidhttp_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/oklog/ulid/v2/ulidhttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	incoming := ulid.MustNew(ulid.Now(), nil)
+	trust := ulidhttp.WithTrust(ulidhttp.TrustAll)
+
+	for _, tc := range []struct {
+		name   string
+		opts   []ulidhttp.Option
+		header string // the middleware's header
+		sent   string // the header sent by the client
+		value  string
+		keep   bool
+		echo   bool
+	}{
+		{"no id", nil, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, "", false, true},
+		{"untrusted by default", nil, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, incoming.String(), false, true},
+		{"trusted", []ulidhttp.Option{trust}, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, incoming.String(), true, true},
+		{"invalid", []ulidhttp.Option{trust}, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, "not-a-ulid", false, true},
+		{"zero", []ulidhttp.Option{trust}, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, ulid.ULID{}.String(), false, true},
+		{"lowercase", []ulidhttp.Option{trust}, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, "01arz3ndektsv4rrffq69g5fai", false, true},
+		{"untrusted", []ulidhttp.Option{ulidhttp.WithTrust(ulidhttp.TrustNone)}, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, incoming.String(), false, true},
+		{"custom header", []ulidhttp.Option{trust, ulidhttp.WithHeader("x-trace-id")}, "X-Trace-Id", "X-Trace-Id", incoming.String(), true, true},
+		{"other header", []ulidhttp.Option{trust, ulidhttp.WithHeader("X-Trace-ID")}, "X-Trace-Id", ulidhttp.DefaultHeader, incoming.String(), false, true},
+		{"no echo", []ulidhttp.Option{trust, ulidhttp.WithEcho(false)}, ulidhttp.DefaultHeader, ulidhttp.DefaultHeader, incoming.String(), true, false},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				seen   ulid.ULID
+				ok     bool
+				header string
+			)
+			h := ulidhttp.Middleware(tc.opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen, ok = ulidhttp.FromContext(r.Context())
+				header = r.Header.Get(tc.header)
+			}))
+
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			req, err := http.NewRequest("GET", srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.value != "" {
+				req.Header.Set(tc.sent, tc.value)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if !ok || seen.IsZero() {
+				t.Fatalf("no request ID in context: got %v", seen)
+			}
+
+			if got, want := seen == incoming, tc.keep; got != want {
+				t.Errorf("kept incoming ID: got %v, want %v", got, want)
+			}
+
+			if got, want := header, seen.String(); got != want {
+				t.Errorf("request header: got %q, want %q", got, want)
+			}
+
+			var want string
+			if tc.echo {
+				want = seen.String()
+			}
+			if got := resp.Header.Get(tc.header); got != want {
+				t.Errorf("response header: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRequestUnchanged(t *testing.T) {
+	t.Parallel()
+
+	var header string
+	h := ulidhttp.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(ulidhttp.DefaultHeader)
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if header == "" {
+		t.Fatal("no request ID in the handler's request header")
+	}
+	if got := r.Header.Get(ulidhttp.DefaultHeader); got != "" {
+		t.Errorf("caller's request header: got %q, want none", got)
+	}
+}
+
+func TestMiddlewareGenerator(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1e9, 0)
+	g, err := ulid.NewGenerator(ulid.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []ulid.ULID
+	h := ulidhttp.Middleware(ulidhttp.WithGenerator(g))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := ulidhttp.FromContext(r.Context())
+		ids = append(ids, id)
+	}))
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if got, want := rec.Header().Get(ulidhttp.DefaultHeader), ids[i].String(); got != want {
+			t.Errorf("response header: got %q, want %q", got, want)
+		}
+	}
+
+	for i, id := range ids {
+		if got, want := id.Time(), ulid.Timestamp(now); got != want {
+			t.Errorf("ids[%d].Time(): got %d, want %d", i, got, want)
+		}
+		if i > 0 && ids[i-1].Compare(id) >= 0 {
+			t.Errorf("ids[%d] >= ids[%d]", i-1, i)
+		}
+	}
+}
+
+func TestTrustNetworks(t *testing.T) {
+	t.Parallel()
+
+	_, internal, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trust := ulidhttp.TrustNetworks(internal)
+
+	for _, tc := range []struct {
+		addr string
+		want bool
+	}{
+		{"10.1.2.3:1234", true},
+		{"10.1.2.3", true},
+		{"192.168.1.1:1234", false},
+		{"[::1]:1234", false},
+		{"garbage", false},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tc.addr
+		if got := trust(r); got != tc.want {
+			t.Errorf("trust(%q): got %v, want %v", tc.addr, got, tc.want)
+		}
+	}
+}
+
+func TestMiddlewareGeneratorContext(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1e9, 0)
+	g, err := ulid.NewGenerator(ulid.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var seen ulid.ULID
+	h := ulidhttp.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = ulid.FromContext(r.Context(), ulidhttp.RequestIDKey)
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r.WithContext(ulid.NewGeneratorContext(r.Context(), g)))
+
+	if got, want := seen.Time(), ulid.Timestamp(now); got != want {
+		t.Errorf("Time: got %d, want %d", got, want)
+	}
+}
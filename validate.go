@@ -0,0 +1,158 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// A Validator checks parsed or scanned ULIDs against a set of policies, to
+// reject corrupt or forged input early. The zero value accepts every ULID that
+// parses.
+type Validator struct {
+	// Strict makes parsing reject invalid characters, as ParseStrict does.
+	Strict bool
+
+	// NotZero rejects zero-value ULIDs, including NULL values when scanning.
+	NotZero bool
+
+	// MinTime, if not zero, rejects ULIDs with a time before it.
+	MinTime time.Time
+
+	// NoFuture rejects ULIDs with a time later than the current time plus
+	// MaxSkew, which accounts for clock skew between machines.
+	NoFuture bool
+	MaxSkew  time.Duration
+
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	// Codec decodes the time of ULIDs. The zero value uses the Unix epoch.
+	Codec Codec
+}
+
+// ValidationError is returned by a Validator for ULIDs that violate one of its
+// policies. It wraps the reason, one of ErrZeroValue, ErrTimeBeforeMin or
+// ErrTimeInFuture.
+type ValidationError struct {
+	// ID is the offending ULID.
+	ID ULID
+
+	// Err is the reason for the failure.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.ID)
+}
+
+// Unwrap returns the reason for the failure.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks id against the Validator's policies, returning a
+// *ValidationError if it violates one of them.
+func (v Validator) Validate(id ULID) error {
+	if v.NotZero && id.IsZero() {
+		return &ValidationError{ID: id, Err: ErrZeroValue}
+	}
+
+	if v.MinTime.IsZero() && !v.NoFuture {
+		return nil
+	}
+
+	t := v.Codec.TimeOf(id)
+	if !v.MinTime.IsZero() && t.Before(v.MinTime) {
+		return &ValidationError{ID: id, Err: ErrTimeBeforeMin}
+	}
+
+	if v.NoFuture {
+		now := time.Now
+		if v.Now != nil {
+			now = v.Now
+		}
+		if t.After(now().Add(v.MaxSkew)) {
+			return &ValidationError{ID: id, Err: ErrTimeInFuture}
+		}
+	}
+
+	return nil
+}
+
+// ParseWith parses an encoded ULID like Parse, or like ParseStrict if
+// v.Strict is set, and then validates it with v.
+func ParseWith(ulid string, v Validator) (id ULID, err error) {
+	if err = parse([]byte(ulid), v.Strict, &id); err != nil {
+		return id, err
+	}
+	return id, v.Validate(id)
+}
+
+// Scanner returns an sql.Scanner that scans into id like ULID.Scan, and then
+// validates the result with v. The ULID is left untouched if scanning or
+// validation fails.
+//
+// Like ULID.Scan, it accepts strings as well as byte slices holding either the
+// 16 byte binary form or the 26 character text encoding, since drivers often
+// return text columns as byte slices. Unlike ULID.Scan, it parses text of
+// either kind strictly if v.Strict is set.
+//
+//	var id ulid.ULID
+//	err := row.Scan(v.Scanner(&id))
+func (v Validator) Scanner(id *ULID) sql.Scanner {
+	return &validatingScanner{v: v, id: id}
+}
+
+type validatingScanner struct {
+	v  Validator
+	id *ULID
+}
+
+func (s *validatingScanner) Scan(src interface{}) (err error) {
+	var id ULID
+	switch x := src.(type) {
+	case nil:
+		// Like ULID.Scan, leave the ULID untouched.
+		if s.v.NotZero {
+			return &ValidationError{Err: ErrZeroValue}
+		}
+		return nil
+	case string:
+		err = parse([]byte(x), s.v.Strict, &id)
+	case []byte:
+		// Text as a byte slice, which ULID.Scan would parse leniently.
+		if len(x) == EncodedSize {
+			err = parse(x, s.v.Strict, &id)
+		} else {
+			err = id.Scan(x)
+		}
+	default:
+		err = id.Scan(src)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.v.Validate(id); err != nil {
+		return err
+	}
+
+	*s.id = id
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 The Oklog Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ulid_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+func TestValidator(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	v := ulid.Validator{
+		NotZero:  true,
+		MinTime:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NoFuture: true,
+		MaxSkew:  time.Minute,
+		Now:      func() time.Time { return now },
+	}
+
+	at := func(t time.Time) ulid.ULID { return ulid.MustNew(ulid.Timestamp(t), nil) }
+
+	for _, tc := range []struct {
+		name string
+		v    ulid.Validator
+		id   ulid.ULID
+		err  error
+	}{
+		{"valid", v, at(now), nil},
+		{"zero", v, ulid.Zero, ulid.ErrZeroValue},
+		{"zero allowed", ulid.Validator{}, ulid.Zero, nil},
+		{"min", v, at(v.MinTime), nil},
+		{"before min", v, at(v.MinTime.Add(-time.Millisecond)), ulid.ErrTimeBeforeMin},
+		{"within skew", v, at(now.Add(time.Minute)), nil},
+		{"beyond skew", v, at(now.Add(time.Minute + time.Millisecond)), ulid.ErrTimeInFuture},
+		{"far future", v, ulid.MaxAt(ulid.MaxTime()), ulid.ErrTimeInFuture},
+		{"far future allowed", ulid.Validator{}, ulid.MaxAt(ulid.MaxTime()), nil},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.v.Validate(tc.id)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got err %v, want %v", err, tc.err)
+			}
+
+			var verr *ulid.ValidationError
+			if err != nil && (!errors.As(err, &verr) || verr.ID != tc.id) {
+				t.Errorf("got err %#v, want a *ValidationError for %s", err, tc.id)
+			}
+		})
+	}
+}
+
+func TestValidatorCodec(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := ulid.NewCodec(epoch)
+	v := ulid.Validator{MinTime: epoch.Add(time.Hour), Codec: c}
+
+	id, _ := c.New(epoch.Add(2*time.Hour), nil)
+	if err := v.Validate(id); err != nil {
+		t.Errorf("got err %v, want none", err)
+	}
+
+	id, _ = c.New(epoch, nil)
+	if err := v.Validate(id); !errors.Is(err, ulid.ErrTimeBeforeMin) {
+		t.Errorf("got err %v, want %v", err, ulid.ErrTimeBeforeMin)
+	}
+}
+
+func TestParseWith(t *testing.T) {
+	t.Parallel()
+
+	const s = "0000XSNJG0MQJHBF4QX1EFD6Y3"
+	bad := s[:25] + "U"
+
+	if _, err := ulid.ParseWith(bad, ulid.Validator{}); err != nil {
+		t.Errorf("lenient: got err %v", err)
+	}
+	if _, err := ulid.ParseWith(bad, ulid.Validator{Strict: true}); !errors.Is(err, ulid.ErrInvalidCharacters) {
+		t.Errorf("strict: got err %v, want %v", err, ulid.ErrInvalidCharacters)
+	}
+
+	v := ulid.Validator{MinTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	id, err := ulid.ParseWith(s, v)
+	if !errors.Is(err, ulid.ErrTimeBeforeMin) {
+		t.Errorf("got err %v, want %v", err, ulid.ErrTimeBeforeMin)
+	}
+	if id != ulid.MustParse(s) {
+		t.Errorf("got ULID %s, want %s", id, s)
+	}
+}
+
+func TestValidatorScanner(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	valid := ulid.MustNew(ulid.Timestamp(now), nil)
+	future := ulid.MustNew(ulid.Timestamp(now.Add(time.Hour)), nil)
+	v := ulid.Validator{Strict: true, NotZero: true, NoFuture: true, MaxSkew: time.Minute}
+
+	for _, tc := range []struct {
+		name string
+		in   interface{}
+		err  error
+	}{
+		{"string", valid.String(), nil},
+		{"bytes", valid[:], nil},
+		{"text-as-bytes", []byte(valid.String()), nil},
+		{"invalid string", valid.String()[:25] + "U", ulid.ErrInvalidCharacters},
+		{"invalid text-as-bytes", []byte(valid.String()[:25] + "U"), ulid.ErrInvalidCharacters},
+		{"short bytes", valid[:10], ulid.ErrDataSize},
+		{"future", future.String(), ulid.ErrTimeInFuture},
+		{"zero", ulid.Zero[:], ulid.ErrZeroValue},
+		{"nil", nil, ulid.ErrZeroValue},
+		{"other", 44, ulid.ErrScanValue},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var id ulid.ULID
+			err := v.Scanner(&id).Scan(tc.in)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got err %v, want %v", err, tc.err)
+			}
+
+			want := valid
+			if tc.err != nil {
+				want = ulid.Zero
+			}
+			if id != want {
+				t.Errorf("got ULID %s, want %s", id, want)
+			}
+		})
+	}
+}